@@ -0,0 +1,194 @@
+package squirrel
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+type commentTagsKey struct{}
+
+// ContextWithCommentTags returns a copy of ctx carrying tags, merged over any
+// tags already in ctx, for use with CommentContext. Typical tags are the
+// application name, the route of the request and its W3C traceparent.
+func ContextWithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range CommentTagsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, commentTagsKey{}, merged)
+}
+
+// CommentTagsFromContext returns the tags added to ctx with
+// ContextWithCommentTags.
+func CommentTagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(commentTagsKey{}).(map[string]string)
+	return tags
+}
+
+// sqlComment renders tags as a comment in the sqlcommenter format, e.g.
+// /*app='x',route='%2Fy'*/, with keys sorted and keys and values URL encoded,
+// so that the comment can hold neither quotes, placeholders nor its end.
+func sqlComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = commentEscape(k) + "='" + commentEscape(tags[k]) + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// withComment adds the comment for tags to the start or end of sql.
+func withComment(sql string, tags map[string]string, prefix bool) string {
+	comment := sqlComment(tags)
+	switch {
+	case comment == "":
+		return sql
+	case prefix:
+		return comment + " " + sql
+	default:
+		return sql + " " + comment
+	}
+}
+
+// Comment adds a comment with the given key/value tags to the end of the
+// query, in the sqlcommenter format used by database tools to attribute
+// statements, e.g.:
+//
+//	SELECT * FROM users /*app='x',route='%2Fy'*/
+//
+// Keys are sorted and keys and values are URL encoded. Use CommentAsPrefix to
+// put the comment at the start of the query instead.
+func (b SelectBuilder) Comment(tags map[string]string) SelectBuilder {
+	return builder.Set(b, "Comment", tags).(SelectBuilder)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b SelectBuilder) CommentContext(ctx context.Context) SelectBuilder {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
+
+// CommentAsPrefix puts the comment added with Comment at the start of the
+// query.
+func (b SelectBuilder) CommentAsPrefix() SelectBuilder {
+	return builder.Set(b, "CommentPrefix", true).(SelectBuilder)
+}
+
+// Comment adds a comment with the given key/value tags to the end of the
+// query.
+//
+// See SelectBuilder.Comment.
+func (b InsertBuilder) Comment(tags map[string]string) InsertBuilder {
+	return builder.Set(b, "Comment", tags).(InsertBuilder)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b InsertBuilder) CommentContext(ctx context.Context) InsertBuilder {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
+
+// CommentAsPrefix puts the comment added with Comment at the start of the
+// query.
+func (b InsertBuilder) CommentAsPrefix() InsertBuilder {
+	return builder.Set(b, "CommentPrefix", true).(InsertBuilder)
+}
+
+// Comment adds a comment with the given key/value tags to the end of the
+// query.
+//
+// See SelectBuilder.Comment.
+func (b UpdateBuilder) Comment(tags map[string]string) UpdateBuilder {
+	return builder.Set(b, "Comment", tags).(UpdateBuilder)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b UpdateBuilder) CommentContext(ctx context.Context) UpdateBuilder {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
+
+// CommentAsPrefix puts the comment added with Comment at the start of the
+// query.
+func (b UpdateBuilder) CommentAsPrefix() UpdateBuilder {
+	return builder.Set(b, "CommentPrefix", true).(UpdateBuilder)
+}
+
+// Comment adds a comment with the given key/value tags to the end of the
+// query.
+//
+// See SelectBuilder.Comment.
+func (b DeleteBuilder) Comment(tags map[string]string) DeleteBuilder {
+	return builder.Set(b, "Comment", tags).(DeleteBuilder)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b DeleteBuilder) CommentContext(ctx context.Context) DeleteBuilder {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
+
+// CommentAsPrefix puts the comment added with Comment at the start of the
+// query.
+func (b DeleteBuilder) CommentAsPrefix() DeleteBuilder {
+	return builder.Set(b, "CommentPrefix", true).(DeleteBuilder)
+}
+
+// Comment adds a comment with the given key/value tags to the end of the
+// query.
+//
+// See SelectBuilder.Comment.
+func (b MergeBuilder) Comment(tags map[string]string) MergeBuilder {
+	return builder.Set(b, "Comment", tags).(MergeBuilder)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b MergeBuilder) CommentContext(ctx context.Context) MergeBuilder {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
+
+// CommentAsPrefix puts the comment added with Comment at the start of the
+// query.
+func (b MergeBuilder) CommentAsPrefix() MergeBuilder {
+	return builder.Set(b, "CommentPrefix", true).(MergeBuilder)
+}
+
+// CommentAsPrefix puts the comments added with Comment on child builders at
+// the start of their queries.
+func (b StatementBuilderType) CommentAsPrefix() StatementBuilderType {
+	return builder.Set(b, "CommentPrefix", true).(StatementBuilderType)
+}
+
+// Comment adds a comment with the given key/value tags to the queries of
+// child builders.
+//
+// See SelectBuilder.Comment.
+func (b StatementBuilderType) Comment(tags map[string]string) StatementBuilderType {
+	return builder.Set(b, "Comment", tags).(StatementBuilderType)
+}
+
+// CommentContext is Comment with the tags added to ctx with
+// ContextWithCommentTags.
+func (b StatementBuilderType) CommentContext(ctx context.Context) StatementBuilderType {
+	return b.Comment(CommentTagsFromContext(ctx))
+}
@@ -0,0 +1,92 @@
+package squirrel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderComment(t *testing.T) {
+	tags := map[string]string{
+		"route":       "/users/:id?x=1",
+		"app":         "billing",
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	b := Select("*").From("users").Where("id = ?", 1).Comment(tags).PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM users WHERE id = $1 " +
+		"/*app='billing',route='%2Fusers%2F%3Aid%3Fx%3D1'," +
+		"traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestCommentEscaping(t *testing.T) {
+	assert.Equal(t, "/*a%20b='it%27s%20%2A%2F%20done'*/", sqlComment(map[string]string{"a b": "it's */ done"}))
+	assert.Equal(t, "", sqlComment(nil))
+}
+
+func TestCommentContext(t *testing.T) {
+	ctx := ContextWithCommentTags(context.Background(), map[string]string{"app": "x", "route": "/a"})
+	ctx = ContextWithCommentTags(ctx, map[string]string{"route": "/b"})
+
+	sql, _, err := Delete("t").Where("id = ?", 1).CommentContext(ctx).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE id = ? /*app='x',route='%2Fb'*/", sql)
+
+	sql, _, err = Update("t").Set("a", 1).CommentContext(context.Background()).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ?", sql)
+}
+
+func TestCommentAsPrefix(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(AtP).CommentAsPrefix()
+	tags := map[string]string{"app": "x"}
+
+	sql, _, err := sb.Insert("t").Values(1).Comment(tags).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "/*app='x'*/ INSERT INTO t VALUES (@p1)", sql)
+
+	sql, _, err = Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().Delete().
+		Comment(tags).Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE /*app='x'*/;", sql)
+}
+
+func TestStatementBuilderComment(t *testing.T) {
+	ctx := ContextWithCommentTags(context.Background(), map[string]string{"app": "x"})
+	sb := StatementBuilder.CommentContext(ctx)
+
+	sql, _, err := sb.Select("*").From("t").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t /*app='x'*/", sql)
+
+	sql, _, err = sb.Update("t").Set("a", 1).Comment(map[string]string{"app": "y"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ? /*app='y'*/", sql)
+}
+
+func TestCommentNested(t *testing.T) {
+	sb := StatementBuilder.Comment(map[string]string{"app": "x"})
+	sub := sb.Select("id").From("u").Where("a = ?", 1)
+
+	sql, _, err := sb.Select("*").
+		From("t").
+		Where(Eq{"id": sub}).
+		Where(Expr("b IN (?)", sub)).
+		Column(Alias(sub, "c")).
+		ToSql()
+	assert.NoError(t, err)
+	expectedSql := "SELECT *, (SELECT id FROM u WHERE a = ?) AS c FROM t " +
+		"WHERE id IN (SELECT id FROM u WHERE a = ?) AND b IN (SELECT id FROM u WHERE a = ?) " +
+		"/*app='x'*/"
+	assert.Equal(t, expectedSql, sql)
+
+	sql, _, err = sb.Insert("t").Select(sub).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t SELECT id FROM u WHERE a = ? /*app='x'*/", sql)
+}
@@ -16,6 +16,11 @@ type CTE struct {
 	Expression Sqlizer
 }
 
+func (c CTE) withDialect(d Dialect) Sqlizer {
+	c.Expression = adaptToDialect(c.Expression, d)
+	return c
+}
+
 // ToSql builds the SQL for a CTE
 func (c CTE) ToSql() (string, []interface{}, error) {
 
@@ -34,7 +39,7 @@ func (c CTE) ToSql() (string, []interface{}, error) {
 	}
 
 	buf.WriteString(" AS (")
-	sql, args, err := c.Expression.ToSql()
+	sql, args, err := nestedToSql(c.Expression)
 	if err != nil {
 		return "", []interface{}{}, err
 	}
@@ -40,3 +40,57 @@ func TestRecursiveCTE(t *testing.T) {
 	assert.Nil(t, err)
 
 }
+
+func TestInsertBuilderWithCTE(t *testing.T) {
+	b := Insert("report").
+		With("recent", Select("id", "total").From("orders").Where(Gt{"created_at": "2024-01-01"})).
+		Columns("order_id", "total").
+		Select(Select("id", "total").From("recent").Where(Gt{"total": 100})).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH recent AS (SELECT id, total FROM orders WHERE created_at > $1) "+
+		"INSERT INTO report (order_id,total) SELECT id, total FROM recent WHERE total > $2", sql)
+	assert.Equal(t, []interface{}{"2024-01-01", 100}, args)
+}
+
+func TestDeleteBuilderWithDataModifyingCTE(t *testing.T) {
+	archive := Insert("orders_archive").
+		Select(Select("*").From("orders").Where(Lt{"created_at": "2020-01-01"})).
+		Returning("id").
+		PlaceholderFormat(Dollar)
+	b := Delete("orders").
+		With("archived", archive).
+		Where("id IN (SELECT id FROM archived)").
+		Where(Eq{"status": "closed"}).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH archived AS (INSERT INTO orders_archive SELECT * FROM orders WHERE created_at < $1 RETURNING id) "+
+		"DELETE FROM orders WHERE id IN (SELECT id FROM archived) AND status = $2", sql)
+	assert.Equal(t, []interface{}{"2020-01-01", "closed"}, args)
+}
+
+func TestCTEWithUpdateAndDeleteExpressions(t *testing.T) {
+	b := Select("*").
+		WithCTE(CTE{
+			Alias:      "moved",
+			Expression: Update("items").Set("bin", 2).Where(Eq{"bin": 1}).Returning("id").PlaceholderFormat(Dollar),
+		}).
+		WithCTE(CTE{
+			Alias:      "purged",
+			Expression: Delete("items").Where(Eq{"bin": 3}).Returning("id").PlaceholderFormat(Dollar),
+		}).
+		From("moved").
+		Where(Gt{"id": 10}).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH moved AS (UPDATE items SET bin = $1 WHERE bin = $2 RETURNING id), "+
+		"purged AS (DELETE FROM items WHERE bin = $3 RETURNING id) "+
+		"SELECT * FROM moved WHERE id > $4", sql)
+	assert.Equal(t, []interface{}{2, 1, 3, 10}, args)
+}
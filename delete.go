@@ -11,15 +11,20 @@ import (
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
+	CTEs              []Sqlizer
 	From              string
 	Joins             []Sqlizer
 	WhereParts        []Sqlizer
 	OrderBys          []string
 	Limit             string
 	Offset            string
+	Returning         []Sqlizer
 	Suffixes          []Sqlizer
+	Comment           map[string]string
+	CommentPrefix     bool
 }
 
 func (d *deleteData) Exec() (sql.Result, error) {
@@ -30,6 +35,18 @@ func (d *deleteData) Exec() (sql.Result, error) {
 }
 
 func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = d.toSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr = withComment(sqlStr, d.Comment, d.CommentPrefix)
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	return
+}
+
+func (d *deleteData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 	if len(d.From) == 0 {
 		err = fmt.Errorf("delete statements must specify a From table")
 		return
@@ -46,12 +63,42 @@ func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(" ")
 	}
 
-	sql.WriteString("DELETE FROM ")
+	if len(d.CTEs) > 0 {
+		sql.WriteString("WITH ")
+		args, err = appendToSql(applyDialect(d.CTEs, d.Dialect), sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sql.WriteString(" ")
+	}
+
+	sql.WriteString("DELETE ")
+
+	top, limitClause, err := dmlLimitOffset(d.Dialect, StatementDelete, d.Limit, d.Offset)
+	if err != nil {
+		return
+	}
+	if len(top) > 0 {
+		sql.WriteString(top)
+		sql.WriteString(" ")
+	}
+
+	sql.WriteString("FROM ")
 	sql.WriteString(d.From)
 
+	output, outputArgs, err := returningToSql(d.Dialect, StatementDelete, ReturningOutput, d.Returning, "DELETED")
+	if err != nil {
+		return
+	}
+	if len(output) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(output)
+		args = append(args, outputArgs...)
+	}
+
 	if len(d.Joins) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
+		args, err = appendToSql(applyDialect(d.Joins, d.Dialect), sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -59,7 +106,7 @@ func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(applyDialect(d.WhereParts, d.Dialect), sql, " AND ", args)
 		if err != nil {
 			return
 		}
@@ -70,14 +117,19 @@ func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(limitClause) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(limitClause)
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	returning, returningArgs, err := returningToSql(d.Dialect, StatementDelete, ReturningClause, d.Returning, "")
+	if err != nil {
+		return
+	}
+	if len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
 	}
 
 	if len(d.Suffixes) > 0 {
@@ -88,7 +140,7 @@ func (d *deleteData) ToSql() (sqlStr string, args []interface{}, err error) {
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
@@ -109,6 +161,12 @@ func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
 }
 
+// Dialect sets the Dialect used to render the query, along with its default
+// PlaceholderFormat.
+func (b DeleteBuilder) Dialect(d Dialect) DeleteBuilder {
+	return setDialect(b, d).(DeleteBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -130,6 +188,11 @@ func (b DeleteBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+func (b DeleteBuilder) toSqlRaw() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.toSqlRaw()
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b DeleteBuilder) MustSql() (string, []interface{}) {
@@ -162,6 +225,13 @@ func (b DeleteBuilder) Where(pred interface{}, args ...interface{}) DeleteBuilde
 	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(DeleteBuilder)
 }
 
+// WhereNamed adds a WHERE expression with named parameters bound from params.
+//
+// See NamedExpr.
+func (b DeleteBuilder) WhereNamed(sql string, params interface{}) DeleteBuilder {
+	return b.Where(NamedExpr(sql, params))
+}
+
 // OrderBy adds ORDER BY expressions to the query.
 func (b DeleteBuilder) OrderBy(orderBys ...string) DeleteBuilder {
 	return builder.Extend(b, "OrderBys", orderBys).(DeleteBuilder)
@@ -187,11 +257,60 @@ func (b DeleteBuilder) SuffixExpr(expr Sqlizer) DeleteBuilder {
 	return builder.Append(b, "Suffixes", expr).(DeleteBuilder)
 }
 
+// With adds a non-recursive CTE to the query.
+func (b DeleteBuilder) With(alias string, expr Sqlizer) DeleteBuilder {
+	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: false, Expression: expr})
+}
+
+// WithRecursive adds a recursive CTE to the query.
+func (b DeleteBuilder) WithRecursive(alias string, expr Sqlizer) DeleteBuilder {
+	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: true, Expression: expr})
+}
+
+// WithCTE adds an arbitrary Sqlizer to the query.
+// The sqlizer will be sandwiched between the keyword WITH and, if there's more than one CTE, a comma.
+func (b DeleteBuilder) WithCTE(cte Sqlizer) DeleteBuilder {
+	return builder.Append(b, "CTEs", cte).(DeleteBuilder)
+}
+
+// SuffixNamed adds an expression with named parameters bound from params to
+// the end of the query.
+//
+// See NamedExpr.
+func (b DeleteBuilder) SuffixNamed(sql string, params interface{}) DeleteBuilder {
+	return b.SuffixExpr(NamedExpr(sql, params))
+}
+
+// Returning adds RETURNING expressions to the query.
+//
+// Under a Dialect using ReturningOutput (SQL Server) they are rendered as an
+// OUTPUT clause, with plain column names qualified by the DELETED pseudo table.
+func (b DeleteBuilder) Returning(columns ...string) DeleteBuilder {
+	return builder.Extend(b, "Returning", newReturningColumns(columns)).(DeleteBuilder)
+}
+
+// ReturningExpr adds a RETURNING expression to the query.
+func (b DeleteBuilder) ReturningExpr(expr Sqlizer) DeleteBuilder {
+	return builder.Append(b, "Returning", expr).(DeleteBuilder)
+}
+
+// Query builds and Querys the query with the Runner set by RunWith.
 func (b DeleteBuilder) Query() (*sql.Rows, error) {
 	data := builder.GetStruct(b).(deleteData)
 	return data.Query()
 }
 
+// QueryRow builds and QueryRows the query with the Runner set by RunWith.
+func (b DeleteBuilder) QueryRow() RowScanner {
+	data := builder.GetStruct(b).(deleteData)
+	return data.QueryRow()
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b DeleteBuilder) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
+}
+
 func (d *deleteData) Query() (*sql.Rows, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
@@ -199,6 +318,17 @@ func (d *deleteData) Query() (*sql.Rows, error) {
 	return QueryWith(d.RunWith, d)
 }
 
+func (d *deleteData) QueryRow() RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, d)
+}
+
 // JoinClause adds a join clause to the query.
 func (b DeleteBuilder) JoinClause(pred interface{}, args ...interface{}) DeleteBuilder {
 	return builder.Append(b, "Joins", newPart(pred, args...)).(DeleteBuilder)
@@ -80,6 +80,20 @@ func TestDeleteWithQuery(t *testing.T) {
 	assert.Equal(t, expectedSql, db.LastQuerySql)
 }
 
+func TestDeleteBuilderQueryRow(t *testing.T) {
+	db := &DBStub{}
+	b := Delete("test").Where("id = ?", 55).Returning("path").RunWith(db)
+
+	var path string
+	err := b.Scan(&path)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM test WHERE id = ? RETURNING path", db.LastQueryRowSql)
+	assert.Equal(t, []interface{}{55}, db.LastQueryRowArgs)
+
+	err = Delete("test").Scan(&path)
+	assert.Equal(t, RunnerNotSet, err)
+}
+
 func TestDeleteBuilderJoin(t *testing.T) {
 	sql, args, err := Delete("orders").
 		Join("customers ON orders.customer_id = customers.id").
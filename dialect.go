@@ -0,0 +1,406 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// Dialect is the interface that describes how SQL differs between database
+// backends.
+//
+// A Dialect can be set on StatementBuilder (or on any individual builder) so
+// that a single query definition renders correctly for each database.
+type Dialect interface {
+	// Name returns a short name for the database, e.g. "postgres".
+	Name() string
+
+	// PlaceholderFormat returns the PlaceholderFormat understood by the
+	// database's drivers.
+	PlaceholderFormat() PlaceholderFormat
+
+	// QuoteIdent quotes an identifier such as a table or column name. Dotted
+	// names (e.g. "schema.table") are quoted part by part.
+	QuoteIdent(ident string) string
+
+	// LimitOffset renders the LIMIT and OFFSET values of a statement; either
+	// may be empty. top is written right after the statement keyword (e.g.
+	// "TOP (10)") and clause after ORDER BY (e.g. "LIMIT 10 OFFSET 5").
+	LimitOffset(limit, offset string) (top, clause string)
+
+	// SupportsILike reports whether the database understands ILIKE. If it does
+	// not, ILike and NotILike are rendered using LOWER() on both operands.
+	SupportsILike() bool
+
+	// BackslashEscapes reports whether the database reads backslashes in
+	// string literals as escape characters, as MySQL does by default. A LIKE
+	// ESCAPE '\' clause is then left out, the backslash being the default
+	// LIKE escape character there.
+	BackslashEscapes() bool
+
+	// UpsertStyle returns the INSERT conflict clause understood by the
+	// database, used to render InsertBuilder.OnConflict.
+	UpsertStyle() UpsertStyle
+
+	// ReturningStyle returns how the database returns rows from statements of
+	// the given kind, used to render Returning.
+	ReturningStyle(kind StatementKind) ReturningStyle
+
+	// SupportsRowLock reports whether the database understands the row
+	// locking clause FOR strength, used to render SelectBuilder.For.
+	SupportsRowLock(strength LockStrength) bool
+
+	// SupportsCompoundRowLock reports whether the database accepts a row
+	// locking clause on the parenthesized operands of a compound query, used
+	// to render SelectBuilder.For with ParenthesizeCompounds.
+	SupportsCompoundRowLock() bool
+
+	// SupportsAnyArray reports whether the database can compare a value with
+	// the elements of a single array parameter, as in "col = ANY(?)", used to
+	// render Eq.AsAny.
+	SupportsAnyArray() bool
+
+	// SupportsMergeDoNothing reports whether the database accepts DO NOTHING
+	// as the action of a MERGE WHEN clause, used to render
+	// MergeBuilder.DoNothing.
+	SupportsMergeDoNothing() bool
+}
+
+var (
+	// Postgres is a Dialect for PostgreSQL.
+	Postgres Dialect = postgresDialect{}
+
+	// MySQL is a Dialect for MySQL.
+	MySQL Dialect = mysqlDialect{}
+
+	// MariaDB is a Dialect for MariaDB 10.5 and later, which differs from
+	// MySQL in supporting RETURNING on INSERT and DELETE.
+	MariaDB Dialect = mariaDBDialect{}
+
+	// SQLite is a Dialect for SQLite.
+	SQLite Dialect = sqliteDialect{}
+
+	// SQLServer is a Dialect for Microsoft SQL Server.
+	SQLServer Dialect = sqlServerDialect{}
+
+	// Oracle is a Dialect for Oracle Database 12c and later.
+	Oracle Dialect = oracleDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) PlaceholderFormat() PlaceholderFormat { return Dollar }
+
+func (postgresDialect) QuoteIdent(ident string) string {
+	return quoteIdent(ident, `"`, `"`)
+}
+
+func (postgresDialect) LimitOffset(limit, offset string) (string, string) {
+	return "", limitOffsetClause(limit, offset, "")
+}
+
+func (postgresDialect) SupportsILike() bool { return true }
+
+func (postgresDialect) BackslashEscapes() bool { return false }
+
+func (postgresDialect) UpsertStyle() UpsertStyle { return UpsertOnConflict }
+
+func (postgresDialect) ReturningStyle(StatementKind) ReturningStyle { return ReturningClause }
+
+func (postgresDialect) SupportsRowLock(LockStrength) bool { return true }
+
+func (postgresDialect) SupportsCompoundRowLock() bool { return false }
+
+func (postgresDialect) SupportsAnyArray() bool { return true }
+
+func (postgresDialect) SupportsMergeDoNothing() bool { return true }
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) PlaceholderFormat() PlaceholderFormat { return Question }
+
+func (mysqlDialect) QuoteIdent(ident string) string {
+	return quoteIdent(ident, "`", "`")
+}
+
+// MySQL requires a LIMIT whenever OFFSET is used; the documented workaround
+// is the largest unsigned BIGINT.
+func (mysqlDialect) LimitOffset(limit, offset string) (string, string) {
+	return "", limitOffsetClause(limit, offset, "18446744073709551615")
+}
+
+func (mysqlDialect) SupportsILike() bool { return false }
+
+func (mysqlDialect) BackslashEscapes() bool { return true }
+
+func (mysqlDialect) UpsertStyle() UpsertStyle { return UpsertOnDuplicateKey }
+
+func (mysqlDialect) ReturningStyle(StatementKind) ReturningStyle { return ReturningUnsupported }
+
+func (mysqlDialect) SupportsRowLock(strength LockStrength) bool {
+	return strength == LockUpdate || strength == LockShare
+}
+
+func (mysqlDialect) SupportsCompoundRowLock() bool { return true }
+
+func (mysqlDialect) SupportsAnyArray() bool { return false }
+
+func (mysqlDialect) SupportsMergeDoNothing() bool { return false }
+
+type mariaDBDialect struct {
+	mysqlDialect
+}
+
+func (mariaDBDialect) Name() string { return "mariadb" }
+
+// MariaDB 10.5+ supports RETURNING on INSERT and DELETE, but not on UPDATE.
+func (mariaDBDialect) ReturningStyle(kind StatementKind) ReturningStyle {
+	if kind == StatementUpdate {
+		return ReturningUnsupported
+	}
+	return ReturningClause
+}
+
+// MariaDB has LOCK IN SHARE MODE but no FOR SHARE.
+func (mariaDBDialect) SupportsRowLock(strength LockStrength) bool { return strength == LockUpdate }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) PlaceholderFormat() PlaceholderFormat { return Question }
+
+func (sqliteDialect) QuoteIdent(ident string) string {
+	return quoteIdent(ident, `"`, `"`)
+}
+
+// SQLite requires a LIMIT whenever OFFSET is used; a negative LIMIT means no
+// limit.
+func (sqliteDialect) LimitOffset(limit, offset string) (string, string) {
+	return "", limitOffsetClause(limit, offset, "-1")
+}
+
+func (sqliteDialect) SupportsILike() bool { return false }
+
+func (sqliteDialect) BackslashEscapes() bool { return false }
+
+func (sqliteDialect) UpsertStyle() UpsertStyle { return UpsertOnConflict }
+
+func (sqliteDialect) ReturningStyle(StatementKind) ReturningStyle { return ReturningClause }
+
+// SQLite locks the whole database and has no row locking clauses.
+func (sqliteDialect) SupportsRowLock(LockStrength) bool { return false }
+
+func (sqliteDialect) SupportsCompoundRowLock() bool { return false }
+
+func (sqliteDialect) SupportsAnyArray() bool { return false }
+
+func (sqliteDialect) SupportsMergeDoNothing() bool { return false }
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() string { return "sqlserver" }
+
+func (sqlServerDialect) PlaceholderFormat() PlaceholderFormat { return AtP }
+
+func (sqlServerDialect) QuoteIdent(ident string) string {
+	return quoteIdent(ident, "[", "]")
+}
+
+// SQL Server has no LIMIT; a bare limit becomes TOP and an offset uses the
+// OFFSET ... FETCH syntax, which also requires an ORDER BY.
+func (sqlServerDialect) LimitOffset(limit, offset string) (string, string) {
+	if offset == "" {
+		if limit == "" {
+			return "", ""
+		}
+		return fmt.Sprintf("TOP (%s)", limit), ""
+	}
+	return "", offsetFetchClause(limit, offset)
+}
+
+func (sqlServerDialect) SupportsILike() bool { return false }
+
+func (sqlServerDialect) BackslashEscapes() bool { return false }
+
+func (sqlServerDialect) UpsertStyle() UpsertStyle { return UpsertUnsupported }
+
+func (sqlServerDialect) ReturningStyle(StatementKind) ReturningStyle { return ReturningOutput }
+
+// SQL Server locks rows with table hints such as WITH (UPDLOCK, READPAST).
+func (sqlServerDialect) SupportsRowLock(LockStrength) bool { return false }
+
+func (sqlServerDialect) SupportsCompoundRowLock() bool { return false }
+
+func (sqlServerDialect) SupportsAnyArray() bool { return false }
+
+func (sqlServerDialect) SupportsMergeDoNothing() bool { return false }
+
+type oracleDialect struct{}
+
+func (oracleDialect) Name() string { return "oracle" }
+
+func (oracleDialect) PlaceholderFormat() PlaceholderFormat { return Colon }
+
+func (oracleDialect) QuoteIdent(ident string) string {
+	return quoteIdent(ident, `"`, `"`)
+}
+
+func (oracleDialect) LimitOffset(limit, offset string) (string, string) {
+	return "", offsetFetchClause(limit, offset)
+}
+
+func (oracleDialect) SupportsILike() bool { return false }
+
+func (oracleDialect) BackslashEscapes() bool { return false }
+
+func (oracleDialect) UpsertStyle() UpsertStyle { return UpsertUnsupported }
+
+// Oracle's RETURNING ... INTO needs output binds, which database/sql cannot
+// express portably.
+func (oracleDialect) ReturningStyle(StatementKind) ReturningStyle { return ReturningUnsupported }
+
+func (oracleDialect) SupportsRowLock(strength LockStrength) bool { return strength == LockUpdate }
+
+func (oracleDialect) SupportsCompoundRowLock() bool { return false }
+
+func (oracleDialect) SupportsAnyArray() bool { return false }
+
+func (oracleDialect) SupportsMergeDoNothing() bool { return false }
+
+// quoteIdent quotes each dot-separated part of ident, doubling any closing
+// quote characters. A "*" part is left as is.
+func quoteIdent(ident, open, close string) string {
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		if p == "*" {
+			continue
+		}
+		parts[i] = open + strings.ReplaceAll(p, close, close+close) + close
+	}
+	return strings.Join(parts, ".")
+}
+
+func limitOffsetClause(limit, offset, noLimit string) string {
+	if limit == "" && offset != "" {
+		limit = noLimit
+	}
+	var clauses []string
+	if limit != "" {
+		clauses = append(clauses, "LIMIT "+limit)
+	}
+	if offset != "" {
+		clauses = append(clauses, "OFFSET "+offset)
+	}
+	return strings.Join(clauses, " ")
+}
+
+func offsetFetchClause(limit, offset string) string {
+	var clauses []string
+	if offset != "" {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %s ROWS", offset))
+	}
+	if limit != "" {
+		if offset == "" {
+			clauses = append(clauses, fmt.Sprintf("FETCH FIRST %s ROWS ONLY", limit))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("FETCH NEXT %s ROWS ONLY", limit))
+		}
+	}
+	return strings.Join(clauses, " ")
+}
+
+func setDialect(b interface{}, d Dialect) interface{} {
+	b = builder.Set(b, "Dialect", d)
+	if d != nil {
+		b = builder.Set(b, "PlaceholderFormat", d.PlaceholderFormat())
+	}
+	return b
+}
+
+// renderLimitOffset returns the TOP and trailing LIMIT/OFFSET SQL for d, falling
+// back to plain LIMIT and OFFSET clauses when no Dialect is set.
+func renderLimitOffset(d Dialect, limit, offset string) (top, clause string) {
+	if d == nil {
+		return "", limitOffsetClause(limit, offset, "")
+	}
+	return d.LimitOffset(limit, offset)
+}
+
+// dmlLimitOffset is renderLimitOffset for UPDATE and DELETE statements, which
+// accept neither the OFFSET ... ROWS nor the FETCH clause of SQL Server and
+// Oracle: a limit or offset that would need them is an error.
+func dmlLimitOffset(d Dialect, kind StatementKind, limit, offset string) (top, clause string, err error) {
+	top, clause = renderLimitOffset(d, limit, offset)
+	if d != nil && (strings.HasPrefix(clause, "OFFSET ") || strings.HasPrefix(clause, "FETCH ")) {
+		return "", "", fmt.Errorf("dialect %s does not support LIMIT and OFFSET in %s statements", d.Name(), kind)
+	}
+	return top, clause, nil
+}
+
+// dialectSqlizer is implemented by Sqlizers whose SQL depends on the Dialect.
+type dialectSqlizer interface {
+	withDialect(d Dialect) Sqlizer
+}
+
+// applyDialect returns parts with every dialect-dependent Sqlizer adapted to d.
+func applyDialect(parts []Sqlizer, d Dialect) []Sqlizer {
+	if d == nil {
+		return parts
+	}
+	adapted := make([]Sqlizer, len(parts))
+	for i, p := range parts {
+		adapted[i] = adaptToDialect(p, d)
+	}
+	return adapted
+}
+
+func adaptToDialect(s Sqlizer, d Dialect) Sqlizer {
+	if ds, ok := s.(dialectSqlizer); ok && d != nil {
+		return ds.withDialect(d)
+	}
+	return s
+}
+
+func (b SelectBuilder) withDialect(d Dialect) Sqlizer {
+	return selectWithDialect(b, d)
+}
+
+// selectWithDialect returns b, a nested subquery, with the Dialect of the
+// enclosing statement unless it has one of its own.
+func selectWithDialect(b SelectBuilder, d Dialect) SelectBuilder {
+	if d == nil {
+		return b
+	}
+	if cur, ok := builder.Get(b, "Dialect"); ok && cur != nil {
+		return b
+	}
+	return builder.Set(b, "Dialect", d).(SelectBuilder)
+}
+
+type identExpr string
+
+// Ident is an identifier, such as a table or column name, quoted with the
+// QuoteIdent of the Dialect of the builder it is rendered in, e.g. "order"
+// becomes `order` for MySQL and "order" for Postgres. Without a Dialect it is
+// rendered as is.
+//
+// Ident can be used wherever a builder takes a Sqlizer, e.g.:
+//
+//	Select().Column(Ident("order")).Where(Expr("? > ?", Ident("limit"), 10))
+func Ident(name string) Sqlizer {
+	return identExpr(name)
+}
+
+func (i identExpr) ToSql() (string, []interface{}, error) {
+	return string(i), nil, nil
+}
+
+func (i identExpr) withDialect(d Dialect) Sqlizer {
+	return identExpr(d.QuoteIdent(string(i)))
+}
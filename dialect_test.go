@@ -0,0 +1,229 @@
+package squirrel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectQuoteIdent(t *testing.T) {
+	assert.Equal(t, `"public"."users"`, Postgres.QuoteIdent("public.users"))
+	assert.Equal(t, `"a""b"`, Postgres.QuoteIdent(`a"b`))
+	assert.Equal(t, "`users`.*", MySQL.QuoteIdent("users.*"))
+	assert.Equal(t, `"users"`, SQLite.QuoteIdent("users"))
+	assert.Equal(t, "[dbo].[a]]b]", SQLServer.QuoteIdent("dbo.a]b"))
+	assert.Equal(t, `"USERS"`, Oracle.QuoteIdent("USERS"))
+}
+
+func TestSelectBuilderDialectLimitOffset(t *testing.T) {
+	b := Select("a").From("t").Where("b = ?", 1).OrderBy("a").Limit(10).Offset(20)
+
+	tests := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{Postgres, "SELECT a FROM t WHERE b = $1 ORDER BY a LIMIT 10 OFFSET 20"},
+		{MySQL, "SELECT a FROM t WHERE b = ? ORDER BY a LIMIT 10 OFFSET 20"},
+		{SQLite, "SELECT a FROM t WHERE b = ? ORDER BY a LIMIT 10 OFFSET 20"},
+		{SQLServer, "SELECT a FROM t WHERE b = @p1 ORDER BY a OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{Oracle, "SELECT a FROM t WHERE b = :1 ORDER BY a OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+	}
+	for _, test := range tests {
+		sql, args, err := b.Dialect(test.dialect).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql, test.dialect.Name())
+		assert.Equal(t, []interface{}{1}, args)
+	}
+}
+
+func TestSelectBuilderDialectLimitOnly(t *testing.T) {
+	b := Select("a").Distinct().From("t").Limit(5)
+
+	sql, _, err := b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT TOP (5) a FROM t", sql)
+
+	sql, _, err = b.Dialect(Oracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT a FROM t FETCH FIRST 5 ROWS ONLY", sql)
+}
+
+func TestSelectBuilderDialectOffsetOnly(t *testing.T) {
+	b := Select("a").From("t").Offset(5)
+
+	sql, _, err := b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t LIMIT 18446744073709551615 OFFSET 5", sql)
+
+	sql, _, err = b.Dialect(SQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t LIMIT -1 OFFSET 5", sql)
+}
+
+func TestDialectILike(t *testing.T) {
+	b := Select("a").From("t").Where(Or{ILike{"name": "sq%"}, NotILike{"name": "%el"}})
+
+	sql, args, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE (name ILIKE $1 OR name NOT ILIKE $2)", sql)
+	assert.Equal(t, []interface{}{"sq%", "%el"}, args)
+
+	sql, args, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE (LOWER(name) LIKE LOWER(?) OR LOWER(name) NOT LIKE LOWER(?))", sql)
+	assert.Equal(t, []interface{}{"sq%", "%el"}, args)
+}
+
+func TestDialectLikeEscape(t *testing.T) {
+	b := Select("a").From("t").Where(ILike{"name": Contains("sq")})
+
+	sql, _, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE name ILIKE $1 ESCAPE '!'", sql)
+
+	sql, _, err = b.Dialect(SQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE LOWER(name) LIKE LOWER(?) ESCAPE '!'", sql)
+
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE LOWER(name) LIKE LOWER(?) ESCAPE '!'", sql)
+
+	// MySQL escapes with a backslash by default and '\' is not a valid literal.
+	b = Select("a").From("t").Where(Like{"name": LikePattern{Pattern: `a\_%`, Escape: '\\'}})
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE name LIKE ?", sql)
+
+	sql, _, err = b.Dialect(MariaDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE name LIKE ?", sql)
+
+	sql, _, err = b.Dialect(customMySQL{MySQL}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE name LIKE ?", sql)
+}
+
+// customMySQL is a user-defined MySQL compatible Dialect.
+type customMySQL struct{ Dialect }
+
+func (customMySQL) Name() string { return "tidb" }
+
+func TestStatementBuilderDialect(t *testing.T) {
+	sb := StatementBuilder.Dialect(SQLServer)
+
+	sql, _, err := sb.Update("t").Set("a", 1).Where("b = ?", 2).Limit(3).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE TOP (3) t SET a = @p1 WHERE b = @p2", sql)
+
+	sql, _, err = sb.Delete("t").Where("b = ?", 2).Limit(3).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE TOP (3) FROM t WHERE b = @p1", sql)
+
+	sql, _, err = sb.Insert("t").Values(1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (@p1)", sql)
+}
+
+func TestDialectLimitOffsetUnsupported(t *testing.T) {
+	_, _, err := Delete("t").Limit(3).Offset(5).Dialect(SQLServer).ToSql()
+	assert.EqualError(t, err, "dialect sqlserver does not support LIMIT and OFFSET in DELETE statements")
+
+	_, _, err = Update("t").Set("a", 1).Limit(3).Dialect(Oracle).ToSql()
+	assert.EqualError(t, err, "dialect oracle does not support LIMIT and OFFSET in UPDATE statements")
+
+	sql, _, err := Update("t").Set("a", 1).Limit(3).Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ? LIMIT 3", sql)
+}
+
+func TestDialectPlaceholderFormatOverride(t *testing.T) {
+	sql, _, err := Select("a").From("t").Where("b = ?", 1).
+		Dialect(Postgres).
+		PlaceholderFormat(Question).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE b = ?", sql)
+}
+
+func TestIdent(t *testing.T) {
+	b := Select().Column(Ident("order")).From("t").
+		Where(Expr("? > ?", Ident("limit"), 10)).
+		OrderByClause(Ident("group"))
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT order FROM t WHERE limit > ? ORDER BY group", sql)
+	assert.Equal(t, []interface{}{10}, args)
+
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `order` FROM t WHERE `limit` > ? ORDER BY `group`", sql)
+
+	sql, _, err = b.Column(Alias(Ident("a.b"), "c")).Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT "order", ("a"."b") AS c FROM t WHERE "limit" > $1 ORDER BY "group"`, sql)
+}
+
+func TestDialectNestedSubqueries(t *testing.T) {
+	sub := Select("id").From("u").Where(ILike{"n": "x"})
+	b := Select("*").FromSelect(sub, "s").
+		JoinSelect(sub, "j", "j.id = s.id").
+		Where(Eq{"id": sub}).
+		Where(In("id", sub)).
+		Where(Exists(sub)).
+		Where(Any("id", "=", sub)).
+		Where(map[string]interface{}{"id": sub}).
+		UnionSelect(sub)
+
+	sql, _, err := b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.NotContains(t, sql, "ILIKE")
+	assert.Equal(t, 8, strings.Count(sql, "LOWER(n) LIKE LOWER(?)"))
+
+	// A subquery's own Dialect is kept.
+	sql, _, err = Select("*").From("t").Where(Exists(sub.Dialect(Postgres))).Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE EXISTS (SELECT id FROM u WHERE n ILIKE ?)", sql)
+}
+
+func TestDialectNestedParts(t *testing.T) {
+	sub := Select("id").From("u").Where(ILike{"n": "x"})
+	ids := []int{1, 2}
+
+	sql, _, err := Update("t").
+		With("c", sub).
+		Set("a", sub).
+		Set("b", Expr("?", Eq{"id": ids}.AsAny())).
+		Dialect(Postgres).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH c AS (SELECT id FROM u WHERE n ILIKE $1) "+
+		"UPDATE t SET a = (SELECT id FROM u WHERE n ILIKE $2), b = id = ANY($3)", sql)
+
+	sql, _, err = Delete("t").With("c", sub).Where("id IN (SELECT id FROM c)").Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH c AS (SELECT id FROM u WHERE LOWER(n) LIKE LOWER(?)) "+
+		"DELETE FROM t WHERE id IN (SELECT id FROM c)", sql)
+
+	sql, _, err = Insert("t").With("c", sub).Select(sub).Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(sql, "LOWER(n) LIKE LOWER(?)"))
+
+	sql, _, err = Select("a").From("t").
+		Window("w", Window().PartitionByClause(Ident("order"))).
+		Column(Over("ROW_NUMBER()", Window().OrderByClause(Ident("limit")))).
+		Dialect(MySQL).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a, ROW_NUMBER() OVER (ORDER BY `limit`) FROM t WINDOW w AS (PARTITION BY `order`)", sql)
+
+	sql, _, err = Merge("t").With("c", sub).Using("s", "").On("t.id = s.id").
+		WhenNotMatched().Insert([]string{"id"}, Ident("order")).
+		Dialect(SQLServer).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH c AS (SELECT id FROM u WHERE LOWER(n) LIKE LOWER(@p1)) "+
+		"MERGE INTO t USING s ON t.id = s.id WHEN NOT MATCHED THEN INSERT (id) VALUES ([order]);", sql)
+}
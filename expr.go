@@ -28,6 +28,17 @@ func Expr(sql string, args ...interface{}) Sqlizer {
 	return expr{sql: sql, args: args}
 }
 
+func (e expr) withDialect(d Dialect) Sqlizer {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		if s, ok := arg.(Sqlizer); ok {
+			arg = adaptToDialect(s, d)
+		}
+		args[i] = arg
+	}
+	return expr{sql: e.sql, args: args}
+}
+
 func (e expr) ToSql() (sql string, args []interface{}, err error) {
 	simple := true
 	for _, arg := range e.args {
@@ -41,44 +52,33 @@ func (e expr) ToSql() (sql string, args []interface{}, err error) {
 
 	buf := &bytes.Buffer{}
 	ap := e.args
-	sp := e.sql
-
-	var isql string
-	var iargs []interface{}
-
-	for err == nil && len(ap) > 0 && len(sp) > 0 {
-		i := strings.Index(sp, "?")
-		if i < 0 {
-			// no more placeholders
-			break
-		}
-		if len(sp) > i+1 && sp[i+1:i+2] == "?" {
-			// escaped "??"; append it and step past
-			buf.WriteString(sp[:i+2])
-			sp = sp[i+2:]
-			continue
-		}
-
-		if as, ok := ap[0].(Sqlizer); ok {
+	segments := splitPlaceholders(e.sql, false)
+	buf.WriteString(segments[0])
+
+	for _, segment := range segments[1:] {
+		if len(ap) == 0 {
+			// no more arguments; leave the placeholder as is
+			buf.WriteString("?")
+		} else if as, ok := ap[0].(Sqlizer); ok {
 			// sqlizer argument; expand it and append the result
-			isql, iargs, err = as.ToSql()
-			buf.WriteString(sp[:i])
+			isql, iargs, err := nestedToSql(as)
+			if err != nil {
+				return "", nil, err
+			}
 			buf.WriteString(isql)
 			args = append(args, iargs...)
+			ap = ap[1:]
 		} else {
 			// normal argument; append it and the placeholder
-			buf.WriteString(sp[:i+1])
+			buf.WriteString("?")
 			args = append(args, ap[0])
+			ap = ap[1:]
 		}
-
-		// step past the argument and placeholder
-		ap = ap[1:]
-		sp = sp[i+1:]
+		buf.WriteString(segment)
 	}
 
-	// append the remaining sql and arguments
-	buf.WriteString(sp)
-	return buf.String(), append(args, ap...), err
+	// append the remaining arguments
+	return buf.String(), append(args, ap...), nil
 }
 
 type concatExpr []interface{}
@@ -89,7 +89,7 @@ func (ce concatExpr) ToSql() (sql string, args []interface{}, err error) {
 		case string:
 			sql += p
 		case Sqlizer:
-			pSql, pArgs, err := p.ToSql()
+			pSql, pArgs, err := nestedToSql(p)
 			if err != nil {
 				return "", nil, err
 			}
@@ -125,8 +125,12 @@ func Alias(expr Sqlizer, alias string) aliasExpr {
 	return aliasExpr{expr, alias}
 }
 
+func (e aliasExpr) withDialect(d Dialect) Sqlizer {
+	return aliasExpr{expr: adaptToDialect(e.expr, d), alias: e.alias}
+}
+
 func (e aliasExpr) ToSql() (sql string, args []interface{}, err error) {
-	sql, args, err = e.expr.ToSql()
+	sql, args, err = nestedToSql(e.expr)
 	if err == nil {
 		sql = fmt.Sprintf("(%s) AS %s", sql, e.alias)
 	}
@@ -134,9 +138,20 @@ func (e aliasExpr) ToSql() (sql string, args []interface{}, err error) {
 }
 
 // Eq is syntactic sugar for use with Where/Having/Set methods.
+//
+// A slice value renders as an IN list and a SelectBuilder value as an IN
+// subquery.
+// Ex:
+//     .Where(Eq{"id": Select("user_id").From("admins")}) == "id IN (SELECT user_id FROM admins)"
 type Eq map[string]interface{}
 
 func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
+	return eq.render(useNotOpr, false)
+}
+
+// render renders eq, with slice values as IN lists or, if anyArray is set, as
+// a single array argument compared with ANY or ALL.
+func (eq Eq) render(useNotOpr, anyArray bool) (sql string, args []interface{}, err error) {
 	if len(eq) == 0 {
 		// Empty Sql{} evaluates to true.
 		sql = sqlTrue
@@ -149,6 +164,7 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		inOpr       = "IN"
 		nullOpr     = "IS"
 		inEmptyExpr = sqlFalse
+		quantifier  = "ANY"
 	)
 
 	if useNotOpr {
@@ -156,6 +172,7 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		inOpr = "NOT IN"
 		nullOpr = "IS NOT"
 		inEmptyExpr = sqlTrue
+		quantifier = "ALL"
 	}
 
 	sortedKeys := getSortedKeys(eq)
@@ -164,7 +181,21 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		val := eq[key]
 
 		switch v := val.(type) {
+		case SelectBuilder:
+			var subSql string
+			var subArgs []interface{}
+			if subSql, subArgs, err = nestedToSql(v); err != nil {
+				return
+			}
+			exprs = append(exprs, fmt.Sprintf("%s %s (%s)", key, inOpr, subSql))
+			args = append(args, subArgs...)
+			continue
 		case driver.Valuer:
+			if anyArray && isArrayValuer(v) {
+				exprs = append(exprs, fmt.Sprintf("%s %s %s(?)", key, equalOpr, quantifier))
+				args = append(args, v)
+				continue
+			}
 			if val, err = v.Value(); err != nil {
 				return
 			}
@@ -182,7 +213,10 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []interface{}, err error) {
 		if val == nil {
 			expr = fmt.Sprintf("%s %s NULL", key, nullOpr)
 		} else {
-			if isListType(val) {
+			if anyArray && isListType(val) {
+				expr = fmt.Sprintf("%s %s %s(?)", key, equalOpr, quantifier)
+				args = append(args, val)
+			} else if isListType(val) {
 				valVal := reflect.ValueOf(val)
 				if valVal.Len() == 0 {
 					expr = inEmptyExpr
@@ -210,6 +244,36 @@ func (eq Eq) ToSql() (sql string, args []interface{}, err error) {
 	return eq.toSQL(false)
 }
 
+func (eq Eq) withDialect(d Dialect) Sqlizer {
+	return eq.subqueriesWithDialect(d)
+}
+
+// subqueriesWithDialect returns a copy of eq with its SelectBuilder values
+// adapted to d.
+func (eq Eq) subqueriesWithDialect(d Dialect) Eq {
+	adapted := make(Eq, len(eq))
+	for key, val := range eq {
+		if sub, ok := val.(SelectBuilder); ok {
+			val = selectWithDialect(sub, d)
+		}
+		adapted[key] = val
+	}
+	return adapted
+}
+
+// AsAny returns eq rendering slice values, under a Dialect supporting it such
+// as Postgres, as a single array argument: "col = ANY(?)" instead of
+// "col IN (?,?,...)". The statement text then does not depend on the length
+// of the slices, so it can be reused by StmtCache. The driver must accept the
+// slice as an array argument, e.g. wrapped with pq.Array. Array values
+// implementing driver.Valuer, such as those returned by pq.Array, are passed
+// to the driver as is.
+//
+// Without such a Dialect, eq is rendered as usual.
+func (eq Eq) AsAny() Sqlizer {
+	return eqAny{eq: eq}
+}
+
 // NotEq is syntactic sugar for use with Where/Having/Set methods.
 // Ex:
 //     .Where(NotEq{"id": 1}) == "id <> 1"
@@ -219,17 +283,165 @@ func (neq NotEq) ToSql() (sql string, args []interface{}, err error) {
 	return Eq(neq).toSQL(true)
 }
 
+func (neq NotEq) withDialect(d Dialect) Sqlizer {
+	return NotEq(Eq(neq).subqueriesWithDialect(d))
+}
+
+// AsAny returns neq rendering slice values, under a Dialect supporting it, as
+// "col <> ALL(?)".
+//
+// See Eq.AsAny.
+func (neq NotEq) AsAny() Sqlizer {
+	return eqAny{eq: Eq(neq), not: true}
+}
+
+type eqAny struct {
+	eq       Eq
+	not      bool
+	anyArray bool
+}
+
+func (e eqAny) ToSql() (sql string, args []interface{}, err error) {
+	return e.eq.render(e.not, e.anyArray)
+}
+
+func (e eqAny) withDialect(d Dialect) Sqlizer {
+	e.eq = e.eq.subqueriesWithDialect(d)
+	e.anyArray = d.SupportsAnyArray()
+	return e
+}
+
+type quantifiedExpr struct {
+	column     string
+	opr        string
+	quantifier string
+	values     interface{}
+}
+
+// Any builds a quantified comparison "column opr ANY(values)", true if the
+// comparison holds for any of values. values is either a single array
+// argument, e.g. wrapped with pq.Array, or a SelectBuilder rendered as a
+// subquery.
+// Ex:
+//     Any("id", "=", pq.Array(ids)) == "id = ANY(?)"
+//     Any("price", ">", Select("price").From("rivals")) == "price > ANY(SELECT price FROM rivals)"
+func Any(column, opr string, values interface{}) Sqlizer {
+	return quantifiedExpr{column: column, opr: opr, quantifier: "ANY", values: values}
+}
+
+// All builds a quantified comparison "column opr ALL(values)", true if the
+// comparison holds for all of values.
+//
+// See Any.
+func All(column, opr string, values interface{}) Sqlizer {
+	return quantifiedExpr{column: column, opr: opr, quantifier: "ALL", values: values}
+}
+
+func (e quantifiedExpr) withDialect(d Dialect) Sqlizer {
+	if sub, ok := e.values.(SelectBuilder); ok {
+		e.values = selectWithDialect(sub, d)
+	}
+	return e
+}
+
+func (e quantifiedExpr) ToSql() (sql string, args []interface{}, err error) {
+	valSql := "?"
+	if sub, ok := e.values.(SelectBuilder); ok {
+		if valSql, args, err = nestedToSql(sub); err != nil {
+			return
+		}
+	} else {
+		args = []interface{}{e.values}
+	}
+	sql = fmt.Sprintf("%s %s %s(%s)", e.column, e.opr, e.quantifier, valSql)
+	return
+}
+
+type inExpr struct {
+	column string
+	values interface{}
+	not    bool
+}
+
+// In builds a "column IN (...)" condition. values is either a slice, rendered
+// as a list of placeholders, or a SelectBuilder, rendered as a subquery.
+// Ex:
+//     In("id", []int{1, 2}) == "id IN (?,?)"
+//     In("id", Select("user_id").From("admins")) == "id IN (SELECT user_id FROM admins)"
+func In(column string, values interface{}) Sqlizer {
+	return inExpr{column: column, values: values}
+}
+
+// NotIn builds a "column NOT IN (...)" condition.
+//
+// See In.
+func NotIn(column string, values interface{}) Sqlizer {
+	return inExpr{column: column, values: values, not: true}
+}
+
+func (e inExpr) withDialect(d Dialect) Sqlizer {
+	if sub, ok := e.values.(SelectBuilder); ok {
+		e.values = selectWithDialect(sub, d)
+	}
+	return e
+}
+
+func (e inExpr) ToSql() (sql string, args []interface{}, err error) {
+	return Eq{e.column: e.values}.toSQL(e.not)
+}
+
+type existsExpr struct {
+	sub SelectBuilder
+	not bool
+}
+
+// Exists builds an "EXISTS (subquery)" condition.
+// Ex:
+//     Exists(Select("1").From("orders").Where("orders.user_id = users.id"))
+func Exists(sub SelectBuilder) Sqlizer {
+	return existsExpr{sub: sub}
+}
+
+// NotExists builds a "NOT EXISTS (subquery)" condition.
+func NotExists(sub SelectBuilder) Sqlizer {
+	return existsExpr{sub: sub, not: true}
+}
+
+func (e existsExpr) withDialect(d Dialect) Sqlizer {
+	e.sub = selectWithDialect(e.sub, d)
+	return e
+}
+
+func (e existsExpr) ToSql() (sql string, args []interface{}, err error) {
+	sql, args, err = nestedToSql(e.sub)
+	if err != nil {
+		return
+	}
+	opr := "EXISTS"
+	if e.not {
+		opr = "NOT EXISTS"
+	}
+	return fmt.Sprintf("%s (%s)", opr, sql), args, nil
+}
+
 // Like is syntactic sugar for use with LIKE conditions.
 // Ex:
 //     .Where(Like{"name": "%irrel"})
+//
+// Use a LikePattern value, e.g. from Contains, to add an ESCAPE clause.
 type Like map[string]interface{}
 
-func (lk Like) toSql(opr string) (sql string, args []interface{}, err error) {
+func (lk Like) toSql(opr string, fold bool, d Dialect) (sql string, args []interface{}, err error) {
 	var exprs []string
-	for key, val := range lk {
+	for _, key := range getSortedKeys(lk) {
 		expr := ""
+		val := lk[key]
 
+		escape := ""
 		switch v := val.(type) {
+		case LikePattern:
+			val = v.Pattern
+			escape = likeEscapeClause(v.Escape, d)
 		case driver.Valuer:
 			if val, err = v.Value(); err != nil {
 				return
@@ -244,7 +456,11 @@ func (lk Like) toSql(opr string) (sql string, args []interface{}, err error) {
 				err = fmt.Errorf("cannot use array or slice with like operators")
 				return
 			} else {
-				expr = fmt.Sprintf("%s %s ?", key, opr)
+				if fold {
+					expr = fmt.Sprintf("LOWER(%s) %s LOWER(?)%s", key, opr, escape)
+				} else {
+					expr = fmt.Sprintf("%s %s ?%s", key, opr, escape)
+				}
 				args = append(args, val)
 			}
 		}
@@ -255,7 +471,11 @@ func (lk Like) toSql(opr string) (sql string, args []interface{}, err error) {
 }
 
 func (lk Like) ToSql() (sql string, args []interface{}, err error) {
-	return lk.toSql("LIKE")
+	return lk.toSql("LIKE", false, nil)
+}
+
+func (lk Like) withDialect(d Dialect) Sqlizer {
+	return dialectLike{like: lk, opr: "LIKE", d: d}
 }
 
 // NotLike is syntactic sugar for use with LIKE conditions.
@@ -264,7 +484,11 @@ func (lk Like) ToSql() (sql string, args []interface{}, err error) {
 type NotLike Like
 
 func (nlk NotLike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(nlk).toSql("NOT LIKE")
+	return Like(nlk).toSql("NOT LIKE", false, nil)
+}
+
+func (nlk NotLike) withDialect(d Dialect) Sqlizer {
+	return dialectLike{like: Like(nlk), opr: "NOT LIKE", d: d}
 }
 
 // ILike is syntactic sugar for use with ILIKE conditions.
@@ -273,7 +497,14 @@ func (nlk NotLike) ToSql() (sql string, args []interface{}, err error) {
 type ILike Like
 
 func (ilk ILike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(ilk).toSql("ILIKE")
+	return Like(ilk).toSql("ILIKE", false, nil)
+}
+
+func (ilk ILike) withDialect(d Dialect) Sqlizer {
+	if d.SupportsILike() {
+		return dialectLike{like: Like(ilk), opr: "ILIKE", d: d}
+	}
+	return dialectLike{like: Like(ilk), opr: "LIKE", fold: true, d: d}
 }
 
 // NotILike is syntactic sugar for use with ILIKE conditions.
@@ -282,7 +513,86 @@ func (ilk ILike) ToSql() (sql string, args []interface{}, err error) {
 type NotILike Like
 
 func (nilk NotILike) ToSql() (sql string, args []interface{}, err error) {
-	return Like(nilk).toSql("NOT ILIKE")
+	return Like(nilk).toSql("NOT ILIKE", false, nil)
+}
+
+func (nilk NotILike) withDialect(d Dialect) Sqlizer {
+	if d.SupportsILike() {
+		return dialectLike{like: Like(nilk), opr: "NOT ILIKE", d: d}
+	}
+	return dialectLike{like: Like(nilk), opr: "NOT LIKE", fold: true, d: d}
+}
+
+// dialectLike is a Like rendered for a Dialect. If fold is set, ILIKE is
+// emulated by lower-casing both operands of a LIKE, for dialects without
+// ILIKE support.
+type dialectLike struct {
+	like Like
+	opr  string
+	fold bool
+	d    Dialect
+}
+
+func (dl dialectLike) ToSql() (sql string, args []interface{}, err error) {
+	return dl.like.toSql(dl.opr, dl.fold, dl.d)
+}
+
+// LikePattern is a value for Like and its variants that matches Pattern with
+// the escape character Escape, rendered as "col LIKE ? ESCAPE '!'". A zero
+// Escape renders no ESCAPE clause.
+type LikePattern struct {
+	Pattern string
+	Escape  rune
+}
+
+// likeEscape is the escape character of the patterns returned by Contains,
+// StartsWith and EndsWith. Unlike a backslash, which MySQL and MariaDB read as
+// an escape in string literals, '!' has no special meaning in any database.
+const likeEscape = '!'
+
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// EscapeLike escapes the LIKE wildcards % and _ in s, and exclamation marks,
+// with an exclamation mark, so that s matches only itself in a LikePattern
+// with Escape '!'.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Contains returns a LikePattern matching strings containing s, which is
+// escaped with EscapeLike so that it is safe to use with user input.
+// Ex:
+//     .Where(Like{"name": Contains("50%")}) == "name LIKE ? ESCAPE '!'" with "%50!%%"
+func Contains(s string) LikePattern {
+	return LikePattern{Pattern: "%" + EscapeLike(s) + "%", Escape: likeEscape}
+}
+
+// StartsWith returns a LikePattern matching strings starting with s.
+//
+// See Contains.
+func StartsWith(s string) LikePattern {
+	return LikePattern{Pattern: EscapeLike(s) + "%", Escape: likeEscape}
+}
+
+// EndsWith returns a LikePattern matching strings ending with s.
+//
+// See Contains.
+func EndsWith(s string) LikePattern {
+	return LikePattern{Pattern: "%" + EscapeLike(s), Escape: likeEscape}
+}
+
+// likeEscapeClause renders the ESCAPE clause for escape. Databases reading
+// backslashes in string literals as escapes, such as MySQL, read '\' as an
+// unterminated string, so the clause is left out there for LikePatterns
+// escaped with a backslash.
+func likeEscapeClause(escape rune, d Dialect) string {
+	switch {
+	case escape == 0:
+		return ""
+	case escape == '\\' && d != nil && d.BackslashEscapes():
+		return ""
+	}
+	return " ESCAPE '" + strings.ReplaceAll(string(escape), "'", "''") + "'"
 }
 
 // Lt is syntactic sugar for use with Where/Having/Set methods.
@@ -364,6 +674,123 @@ func (gtOrEq GtOrEq) ToSql() (sql string, args []interface{}, err error) {
 	return Lt(gtOrEq).toSql(true, true)
 }
 
+// Between is syntactic sugar for use with BETWEEN conditions. Values are
+// two-element arrays or slices of the inclusive bounds; a nil bound is left
+// out, so that the range is unbounded on that side. A key with two nil bounds
+// adds no condition, here as in NotBetween and HalfOpen.
+// Ex:
+//     .Where(Between{"age": [2]interface{}{18, 65}}) == "age BETWEEN ? AND ?"
+//     .Where(Between{"age": [2]interface{}{18, nil}}) == "age >= ?"
+type Between map[string]interface{}
+
+func (b Between) ToSql() (sql string, args []interface{}, err error) {
+	return rangeToSql(b, func(key string, lo, hi interface{}) (string, []interface{}) {
+		switch {
+		case lo == nil && hi == nil:
+			return "", nil
+		case lo == nil:
+			return fmt.Sprintf("%s <= ?", key), []interface{}{hi}
+		case hi == nil:
+			return fmt.Sprintf("%s >= ?", key), []interface{}{lo}
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", key), []interface{}{lo, hi}
+	})
+}
+
+// NotBetween is syntactic sugar for use with NOT BETWEEN conditions. Like
+// Between, it leaves out a key with two nil bounds rather than rendering a
+// condition that matches no rows.
+// Ex:
+//     .Where(NotBetween{"age": [2]interface{}{18, 65}}) == "age NOT BETWEEN ? AND ?"
+//
+// See Between.
+type NotBetween Between
+
+func (nb NotBetween) ToSql() (sql string, args []interface{}, err error) {
+	return rangeToSql(nb, func(key string, lo, hi interface{}) (string, []interface{}) {
+		switch {
+		case lo == nil && hi == nil:
+			return "", nil
+		case lo == nil:
+			return fmt.Sprintf("%s > ?", key), []interface{}{hi}
+		case hi == nil:
+			return fmt.Sprintf("%s < ?", key), []interface{}{lo}
+		}
+		return fmt.Sprintf("%s NOT BETWEEN ? AND ?", key), []interface{}{lo, hi}
+	})
+}
+
+// HalfOpen is syntactic sugar for half-open range conditions, with the lower
+// bound inclusive and the upper bound exclusive, as is usual for time ranges.
+// Values are as for Between.
+// Ex:
+//     .Where(HalfOpen{"at": [2]interface{}{start, end}}) == "at >= ? AND at < ?"
+type HalfOpen Between
+
+func (ho HalfOpen) ToSql() (sql string, args []interface{}, err error) {
+	return rangeToSql(ho, func(key string, lo, hi interface{}) (string, []interface{}) {
+		switch {
+		case lo == nil && hi == nil:
+			return "", nil
+		case lo == nil:
+			return fmt.Sprintf("%s < ?", key), []interface{}{hi}
+		case hi == nil:
+			return fmt.Sprintf("%s >= ?", key), []interface{}{lo}
+		}
+		return fmt.Sprintf("%s >= ? AND %s < ?", key, key), []interface{}{lo, hi}
+	})
+}
+
+// rangeToSql renders the range of each key of m in sorted order with render,
+// which returns an empty string for ranges without bounds.
+func rangeToSql(m map[string]interface{}, render func(key string, lo, hi interface{}) (string, []interface{})) (sql string, args []interface{}, err error) {
+	var exprs []string
+	for _, key := range getSortedKeys(m) {
+		r := reflect.ValueOf(m[key])
+		if (r.Kind() != reflect.Array && r.Kind() != reflect.Slice) || r.Len() != 2 {
+			err = fmt.Errorf("range for %s must be an array or slice of two bounds, not %T", key, m[key])
+			return
+		}
+		var bounds [2]interface{}
+		for i := range bounds {
+			if bounds[i], err = rangeBound(r.Index(i).Interface()); err != nil {
+				return
+			}
+		}
+		expr, exprArgs := render(key, bounds[0], bounds[1])
+		if expr != "" {
+			exprs = append(exprs, expr)
+			args = append(args, exprArgs...)
+		}
+	}
+	if len(exprs) == 0 {
+		return sqlTrue, nil, nil
+	}
+	sql = strings.Join(exprs, " AND ")
+	return
+}
+
+// rangeBound returns the value of a bound, resolving driver.Valuers and
+// pointers, with nil meaning unbounded.
+func rangeBound(val interface{}) (interface{}, error) {
+	if r := reflect.ValueOf(val); r.Kind() == reflect.Ptr && r.IsNil() {
+		return nil, nil
+	}
+	if v, ok := val.(driver.Valuer); ok {
+		var err error
+		if val, err = v.Value(); err != nil {
+			return nil, err
+		}
+	}
+	if r := reflect.ValueOf(val); r.Kind() == reflect.Ptr {
+		val = r.Elem().Interface()
+	}
+	if val != nil && isListType(val) {
+		return nil, fmt.Errorf("cannot use array or slice as a range bound")
+	}
+	return val, nil
+}
+
 type conj []Sqlizer
 
 func (c conj) join(sep, defaultExpr string) (sql string, args []interface{}, err error) {
@@ -394,6 +821,10 @@ func (a And) ToSql() (string, []interface{}, error) {
 	return conj(a).join(" AND ", sqlTrue)
 }
 
+func (a And) withDialect(d Dialect) Sqlizer {
+	return And(applyDialect(a, d))
+}
+
 // Or conjunction Sqlizers
 type Or conj
 
@@ -401,6 +832,10 @@ func (o Or) ToSql() (string, []interface{}, error) {
 	return conj(o).join(" OR ", sqlFalse)
 }
 
+func (o Or) withDialect(d Dialect) Sqlizer {
+	return Or(applyDialect(o, d))
+}
+
 func getSortedKeys(exp map[string]interface{}) []string {
 	sortedKeys := make([]string, 0, len(exp))
 	for k := range exp {
@@ -410,6 +845,21 @@ func getSortedKeys(exp map[string]interface{}) []string {
 	return sortedKeys
 }
 
+// isArrayValuer reports whether v holds an array, like the driver.Valuers
+// returned by pq.Array: a slice type such as pq.Int64Array, or a struct
+// wrapping a slice such as pq.GenericArray.
+func isArrayValuer(v driver.Valuer) bool {
+	r := reflect.Indirect(reflect.ValueOf(v))
+	if r.Kind() == reflect.Struct && r.NumField() == 1 {
+		r = r.Field(0)
+		if r.Kind() == reflect.Interface {
+			r = r.Elem()
+		}
+		r = reflect.Indirect(r)
+	}
+	return r.Kind() == reflect.Slice && r.Type().Elem().Kind() != reflect.Uint8
+}
+
 func isListType(val interface{}) bool {
 	if driver.IsValue(val) {
 		return false
@@ -2,11 +2,25 @@ package squirrel
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestExprSqlizerArgSkipsLiterals(t *testing.T) {
+	b := Expr("a = '?' AND b = ?", Expr("LOWER(?)", "x"))
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "a = '?' AND b = LOWER(?)"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{"x"}
+	assert.Equal(t, expectedArgs, args)
+}
+
 func TestConcatExpr(t *testing.T) {
 	b := ConcatExpr("COALESCE(name,", Expr("CONCAT(?,' ',?)", "f", "l"), ")")
 	sql, args, err := b.ToSql()
@@ -119,6 +133,182 @@ func TestEqBytesToSql(t *testing.T) {
 	assert.Equal(t, expectedArgs, args)
 }
 
+func TestEqSubqueryToSql(t *testing.T) {
+	sub := StatementBuilder.PlaceholderFormat(Dollar).
+		Select("user_id").From("admins").Where(Eq{"active": true})
+	b := Select("*").From("users").
+		Where(Eq{"org": 1, "id": sub}).
+		Where(NotEq{"id": sub.Where("role = ?", "root")}).
+		PlaceholderFormat(Dollar)
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM users WHERE id IN (SELECT user_id FROM admins WHERE active = $1) AND org = $2 " +
+		"AND id NOT IN (SELECT user_id FROM admins WHERE active = $3 AND role = $4)"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{true, 1, true, "root"}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestInToSql(t *testing.T) {
+	sub := Select("user_id").From("admins").Where(Eq{"active": true})
+	b := And{In("id", sub), NotIn("org", []int{1, 2})}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "(id IN (SELECT user_id FROM admins WHERE active = ?) AND org NOT IN (?,?))"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{true, 1, 2}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestExistsToSql(t *testing.T) {
+	orders := Select("1").From("orders").Where("orders.user_id = users.id").Where(Gt{"total": 100})
+	b := Select("*").From("users").
+		Where(Eq{"org": 1}).
+		Where(Exists(orders)).
+		Where(NotExists(Select("1").From("bans").Where("bans.user_id = users.id AND bans.until > ?", 5))).
+		PlaceholderFormat(Dollar)
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT * FROM users WHERE org = $1 " +
+		"AND EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id AND total > $2) " +
+		"AND NOT EXISTS (SELECT 1 FROM bans WHERE bans.user_id = users.id AND bans.until > $3)"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{1, 100, 5}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestAnyAllToSql(t *testing.T) {
+	ids := []int{1, 2, 3}
+	b := And{
+		Any("id", "=", ids),
+		All("price", ">", Select("price").From("rivals").Where(Eq{"region": "eu"})),
+	}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "(id = ANY(?) AND price > ALL(SELECT price FROM rivals WHERE region = ?))"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{ids, "eu"}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestEqAsAny(t *testing.T) {
+	ids := []int{1, 2}
+	b := Select("*").From("users").
+		Where(Eq{"id": ids, "org": 1}.AsAny()).
+		Where(NotEq{"role": []string{}}.AsAny())
+
+	sql, args, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ANY($1) AND org = $2 AND role <> ALL($3)", sql)
+	assert.Equal(t, []interface{}{ids, 1, []string{}}, args)
+
+	sql, args, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id IN (?,?) AND org = ? AND (1=1)", sql)
+	assert.Equal(t, []interface{}{1, 2, 1}, args)
+}
+
+// int64Array and genericArray mimic the driver.Valuers returned by pq.Array.
+type int64Array []int64
+
+func (a int64Array) Value() (driver.Value, error) {
+	return fmt.Sprint([]int64(a)), nil
+}
+
+type genericArray struct{ A interface{} }
+
+func (a genericArray) Value() (driver.Value, error) {
+	return fmt.Sprint(a.A), nil
+}
+
+func TestEqAsAnyValuer(t *testing.T) {
+	ids := &int64Array{1, 2}
+	orgs := genericArray{[]int{3}}
+	tenant := sql.NullInt64{Int64: 4, Valid: true}
+	b := Select("*").From("users").
+		Where(Eq{"id": ids, "org": orgs, "tenant": tenant}.AsAny()).
+		Where(NotEq{"role": genericArray{&[]string{"x"}}}.AsAny())
+
+	sql, args, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	expectedSql := "SELECT * FROM users " +
+		"WHERE id = ANY($1) AND org = ANY($2) AND tenant = $3 AND role <> ALL($4)"
+	assert.Equal(t, expectedSql, sql)
+	expectedArgs := []interface{}{ids, orgs, int64(4), genericArray{&[]string{"x"}}}
+	assert.Equal(t, expectedArgs, args)
+
+	sql, args, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? AND org = ? AND tenant = ? AND role <> ?", sql)
+	assert.Equal(t, []interface{}{"[1 2]", "[3]", int64(4), "&[x]"}, args)
+}
+
+func TestBetweenToSql(t *testing.T) {
+	b := Between{
+		"c": [2]interface{}{nil, 9},
+		"a": [2]interface{}{1, 5},
+		"b": []interface{}{sql.NullInt64{Int64: 2, Valid: true}, sql.NullInt64{}},
+		"d": [2]interface{}{nil, nil},
+	}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "a BETWEEN ? AND ? AND b >= ? AND c <= ?"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{1, 5, int64(2), 9}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestNotBetweenToSql(t *testing.T) {
+	var hi *int
+	b := NotBetween{"a": [2]int{1, 5}, "b": [2]interface{}{3, hi}, "c": [2]interface{}{nil, nil}}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "a NOT BETWEEN ? AND ? AND b < ?"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{1, 5, 3}
+	assert.Equal(t, expectedArgs, args)
+
+	// Unbounded ranges are left out by all range types alike.
+	unbounded := [2]interface{}{nil, nil}
+	for _, s := range []Sqlizer{Between{"c": unbounded}, NotBetween{"c": unbounded}, HalfOpen{"c": unbounded}} {
+		sql, args, err = s.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, sqlTrue, sql)
+		assert.Empty(t, args)
+	}
+}
+
+func TestHalfOpenToSql(t *testing.T) {
+	b := HalfOpen{"at": [2]interface{}{10, 20}, "until": [2]interface{}{nil, 30}}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "at >= ? AND at < ? AND until < ?"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{10, 20, 30}
+	assert.Equal(t, expectedArgs, args)
+
+	sql, _, err = HalfOpen{"at": [2]interface{}{nil, nil}}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sql)
+
+	_, _, err = HalfOpen{"at": 1}.ToSql()
+	assert.Error(t, err)
+}
+
 func TestLtToSql(t *testing.T) {
 	b := Lt{"id": 1}
 	sql, args, err := b.ToSql()
@@ -385,6 +575,37 @@ func TestNotILikeToSql(t *testing.T) {
 	assert.Equal(t, expectedArgs, args)
 }
 
+func TestLikeOrder(t *testing.T) {
+	b := NotILike{"c": "%3", "a": "%1", "b": "%2"}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "a NOT ILIKE ? AND b NOT ILIKE ? AND c NOT ILIKE ?"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{"%1", "%2", "%3"}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestLikePatternToSql(t *testing.T) {
+	b := Like{
+		"a": Contains(`50%_off\`),
+		"b": StartsWith("x_"),
+		"c": EndsWith("%"),
+		"d": LikePattern{Pattern: `a\%%`, Escape: '\\'},
+		"e": Contains("hi!"),
+	}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "a LIKE ? ESCAPE '!' AND b LIKE ? ESCAPE '!' AND c LIKE ? ESCAPE '!' " +
+		`AND d LIKE ? ESCAPE '\' AND e LIKE ? ESCAPE '!'`
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{`%50!%!_off\%`, "x!_%", "%!%", `a\%%`, "%hi!!%"}
+	assert.Equal(t, expectedArgs, args)
+}
+
 func TestSqlEqOrder(t *testing.T) {
 	b := Eq{"a": 1, "b": 2, "c": 3}
 	sql, args, err := b.ToSql()
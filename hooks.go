@@ -0,0 +1,151 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/lann/builder"
+)
+
+// Hook is the interface for code run around every statement executed through
+// a Runner wrapped by WrapHooks or StatementBuilderType.WithHooks, e.g. for
+// logging, metrics or tracing.
+//
+// Before is called before the statement is executed; the context it returns
+// is passed to the database and to After. After is called once the statement
+// has run with its error and the time it took. For QueryRow that is when Scan
+// is called, as database/sql only reports errors there.
+//
+// Statements run without a context, e.g. with Exec, get context.Background().
+type Hook interface {
+	Before(ctx context.Context, query string, args []interface{}) context.Context
+	After(ctx context.Context, query string, args []interface{}, err error, elapsed time.Duration)
+}
+
+type hookRunner struct {
+	runner BaseRunner
+	hooks  []Hook
+}
+
+// WrapHooks returns a RunnerContext running statements with runner, calling
+// hooks around each of them. Before hooks are called in order and After hooks
+// in reverse order, so the first hook wraps all the others. runner may be a
+// *sql.DB or *sql.Tx.
+//
+// The context methods return NoContextSupport if runner does not support
+// them.
+func WrapHooks(runner BaseRunner, hooks ...Hook) RunnerContext {
+	if r, ok := runner.(*hookRunner); ok {
+		runner = r.runner
+		hooks = append(append([]Hook{}, r.hooks...), hooks...)
+	}
+	runner = wrapStdSqlRunner(runner)
+	return &hookRunner{runner: runner, hooks: hooks}
+}
+
+// before calls the Before hooks and returns a function calling the After
+// hooks.
+func (r *hookRunner) before(ctx context.Context, query string, args []interface{}) (context.Context, func(error)) {
+	ctxs := make([]context.Context, len(r.hooks))
+	for i, h := range r.hooks {
+		ctx = h.Before(ctx, query, args)
+		ctxs[i] = ctx
+	}
+	start := time.Now()
+	return ctx, func(err error) {
+		elapsed := time.Since(start)
+		for i := len(r.hooks) - 1; i >= 0; i-- {
+			r.hooks[i].After(ctxs[i], query, args, err, elapsed)
+		}
+	}
+}
+
+func (r *hookRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	_, after := r.before(context.Background(), query, args)
+	res, err := r.runner.Exec(query, args...)
+	after(err)
+	return res, err
+}
+
+func (r *hookRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	_, after := r.before(context.Background(), query, args)
+	rows, err := r.runner.Query(query, args...)
+	after(err)
+	return rows, err
+}
+
+func (r *hookRunner) QueryRow(query string, args ...interface{}) RowScanner {
+	queryRower, ok := r.runner.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	_, after := r.before(context.Background(), query, args)
+	return &hookRow{RowScanner: queryRower.QueryRow(query, args...), after: after}
+}
+
+func (r *hookRunner) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	execer, ok := r.runner.(ExecerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	ctx, after := r.before(ctx, query, args)
+	res, err := execer.ExecContext(ctx, query, args...)
+	after(err)
+	return res, err
+}
+
+func (r *hookRunner) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	queryer, ok := r.runner.(QueryerContext)
+	if !ok {
+		return nil, NoContextSupport
+	}
+	ctx, after := r.before(ctx, query, args)
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	after(err)
+	return rows, err
+}
+
+func (r *hookRunner) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
+	queryRower, ok := r.runner.(QueryRowerContext)
+	if !ok {
+		return &Row{err: NoContextSupport}
+	}
+	ctx, after := r.before(ctx, query, args)
+	return &hookRow{RowScanner: queryRower.QueryRowContext(ctx, query, args...), after: after}
+}
+
+// hookRow calls the After hooks of a QueryRow on its first Scan.
+type hookRow struct {
+	RowScanner
+	after func(error)
+	once  sync.Once
+}
+
+func (r *hookRow) Scan(dest ...interface{}) error {
+	err := r.RowScanner.Scan(dest...)
+	r.once.Do(func() { r.after(err) })
+	return err
+}
+
+// WithHooks adds hooks to be called around every statement executed by child
+// builders, by wrapping their Runner with WrapHooks. It applies to the Runner
+// already set with RunWith as well as to any set later.
+func (b StatementBuilderType) WithHooks(hooks ...Hook) StatementBuilderType {
+	if prev, ok := builder.Get(b, "hooks"); ok {
+		hooks = append(append([]Hook{}, prev.([]Hook)...), hooks...)
+	}
+	b = builder.Set(b, "hooks", hooks).(StatementBuilderType)
+	if runner, ok := builder.Get(b, "RunWith"); ok && runner != nil {
+		b = setRunWith(b, unwrapHooks(runner.(BaseRunner))).(StatementBuilderType)
+	}
+	return b
+}
+
+func unwrapHooks(runner BaseRunner) BaseRunner {
+	if r, ok := runner.(*hookRunner); ok {
+		return r.runner
+	}
+	return runner
+}
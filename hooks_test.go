@@ -0,0 +1,86 @@
+package squirrel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookKey struct{}
+
+type recordingHook struct {
+	name  string
+	calls *[]string
+}
+
+func (h recordingHook) Before(ctx context.Context, query string, args []interface{}) context.Context {
+	*h.calls = append(*h.calls, fmt.Sprintf("%s before %s %v", h.name, query, args))
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h recordingHook) After(ctx context.Context, query string, args []interface{}, err error, elapsed time.Duration) {
+	*h.calls = append(*h.calls, fmt.Sprintf("%s after %s %v %v ctx=%v", h.name, query, args, err, ctx.Value(hookKey{})))
+}
+
+func TestWrapHooks(t *testing.T) {
+	var calls []string
+	db := &DBStub{}
+	runner := WrapHooks(db, recordingHook{"a", &calls}, recordingHook{"b", &calls})
+
+	_, err := Update("t").Set("x", 1).RunWith(runner).Exec()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET x = ?", db.LastExecSql)
+
+	expected := []string{
+		"a before UPDATE t SET x = ? [1]",
+		"b before UPDATE t SET x = ? [1]",
+		"b after UPDATE t SET x = ? [1] <nil> ctx=b",
+		"a after UPDATE t SET x = ? [1] <nil> ctx=a",
+	}
+	assert.Equal(t, expected, calls)
+}
+
+func TestWrapHooksQueryRow(t *testing.T) {
+	var calls []string
+	db := &DBStub{}
+	row := Select("x").From("t").RunWith(WrapHooks(db, recordingHook{"a", &calls})).QueryRowContext(ctx)
+
+	assert.Equal(t, []string{"a before SELECT x FROM t []"}, calls)
+	assert.NoError(t, row.Scan())
+	assert.NoError(t, row.Scan())
+	assert.Equal(t, []string{"a before SELECT x FROM t []", "a after SELECT x FROM t [] <nil> ctx=a"}, calls)
+}
+
+func TestWrapHooksNoContextSupport(t *testing.T) {
+	var calls []string
+	runner := WrapHooks(struct{ BaseRunner }{&DBStub{}}, recordingHook{"a", &calls})
+
+	_, err := runner.ExecContext(ctx, "SELECT 1")
+	assert.Equal(t, NoContextSupport, err)
+	assert.Empty(t, calls)
+}
+
+func TestStatementBuilderWithHooks(t *testing.T) {
+	var calls []string
+	db := &DBStub{}
+
+	sb := StatementBuilder.RunWith(db).WithHooks(recordingHook{"a", &calls})
+	sb.Delete("t").Where(Eq{"id": 1}).ExecContext(ctx)
+	assert.Equal(t, "DELETE FROM t WHERE id = ?", db.LastExecSql)
+	assert.Len(t, calls, 2)
+
+	// Hooks also wrap a Runner set later, and are not applied twice.
+	calls = nil
+	other := &DBStub{}
+	sb.WithHooks(recordingHook{"b", &calls}).Insert("t").Values(1).RunWith(other).Exec()
+	assert.Equal(t, "INSERT INTO t VALUES (?)", other.LastExecSql)
+	assert.Equal(t, []string{
+		"a before INSERT INTO t VALUES (?) [1]",
+		"b before INSERT INTO t VALUES (?) [1]",
+		"b after INSERT INTO t VALUES (?) [1] <nil> ctx=b",
+		"a after INSERT INTO t VALUES (?) [1] <nil> ctx=a",
+	}, calls)
+}
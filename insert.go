@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -13,16 +14,28 @@ import (
 )
 
 type insertData struct {
-	PlaceholderFormat PlaceholderFormat
-	RunWith           BaseRunner
-	Prefixes          []Sqlizer
-	StatementKeyword  string
-	Options           []string
-	Into              string
-	Columns           []string
-	Values            [][]interface{}
-	Suffixes          []Sqlizer
-	Select            *SelectBuilder
+	PlaceholderFormat  PlaceholderFormat
+	Dialect            Dialect
+	RunWith            BaseRunner
+	Prefixes           []Sqlizer
+	CTEs               []Sqlizer
+	StatementKeyword   string
+	Options            []string
+	Into               string
+	Columns            []string
+	Values             [][]interface{}
+	Conflict           bool
+	ConflictTarget     []string
+	ConflictConstraint string
+	ConflictAction     string
+	ConflictSet        []setClause
+	ConflictWhereParts []Sqlizer
+	DuplicateKey       bool
+	Returning          []Sqlizer
+	Suffixes           []Sqlizer
+	Comment            map[string]string
+	CommentPrefix      bool
+	Select             *SelectBuilder
 }
 
 func (d *insertData) Exec() (sql.Result, error) {
@@ -51,6 +64,18 @@ func (d *insertData) QueryRow() RowScanner {
 }
 
 func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = d.toSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr = withComment(sqlStr, d.Comment, d.CommentPrefix)
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	return
+}
+
+func (d *insertData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 	if len(d.Into) == 0 {
 		err = errors.New("insert statements must specify a table")
 		return
@@ -71,6 +96,15 @@ func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(" ")
 	}
 
+	if len(d.CTEs) > 0 {
+		sql.WriteString("WITH ")
+		args, err = appendToSql(applyDialect(d.CTEs, d.Dialect), sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sql.WriteString(" ")
+	}
+
 	if d.StatementKeyword == "" {
 		sql.WriteString("INSERT ")
 	} else {
@@ -93,6 +127,16 @@ func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(") ")
 	}
 
+	output, outputArgs, err := returningToSql(d.Dialect, StatementInsert, ReturningOutput, d.Returning, "INSERTED")
+	if err != nil {
+		return
+	}
+	if len(output) > 0 {
+		sql.WriteString(output)
+		sql.WriteString(" ")
+		args = append(args, outputArgs...)
+	}
+
 	if d.Select != nil {
 		args, err = d.appendSelectToSQL(sql, args)
 	} else {
@@ -102,6 +146,21 @@ func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
 		return
 	}
 
+	args, err = d.appendConflictToSQL(sql, args)
+	if err != nil {
+		return
+	}
+
+	returning, returningArgs, err := returningToSql(d.Dialect, StatementInsert, ReturningClause, d.Returning, "")
+	if err != nil {
+		return
+	}
+	if len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
+
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
 		args, err = appendToSql(d.Suffixes, sql, " ", args)
@@ -110,7 +169,7 @@ func (d *insertData) ToSql() (sqlStr string, args []interface{}, err error) {
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
@@ -126,7 +185,7 @@ func (d *insertData) appendValuesToSQL(w io.Writer, args []interface{}) ([]inter
 		valueStrings := make([]string, len(row))
 		for v, val := range row {
 			if vs, ok := val.(Sqlizer); ok {
-				vsql, vargs, err := vs.ToSql()
+				vsql, vargs, err := nestedToSql(adaptToDialect(vs, d.Dialect))
 				if err != nil {
 					return nil, err
 				}
@@ -150,7 +209,7 @@ func (d *insertData) appendSelectToSQL(w io.Writer, args []interface{}) ([]inter
 		return args, errors.New("select clause for insert statements are not set")
 	}
 
-	selectClause, sArgs, err := d.Select.ToSql()
+	selectClause, sArgs, err := selectWithDialect(*d.Select, d.Dialect).toSqlRaw()
 	if err != nil {
 		return args, err
 	}
@@ -178,6 +237,12 @@ func (b InsertBuilder) PlaceholderFormat(f PlaceholderFormat) InsertBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(InsertBuilder)
 }
 
+// Dialect sets the Dialect used to render the query, along with its default
+// PlaceholderFormat.
+func (b InsertBuilder) Dialect(d Dialect) InsertBuilder {
+	return setDialect(b, d).(InsertBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -216,6 +281,11 @@ func (b InsertBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+func (b InsertBuilder) toSqlRaw() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.toSqlRaw()
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b InsertBuilder) MustSql() (string, []interface{}) {
@@ -241,6 +311,22 @@ func (b InsertBuilder) Options(options ...string) InsertBuilder {
 	return builder.Extend(b, "Options", options).(InsertBuilder)
 }
 
+// With adds a non-recursive CTE to the query.
+func (b InsertBuilder) With(alias string, expr Sqlizer) InsertBuilder {
+	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: false, Expression: expr})
+}
+
+// WithRecursive adds a recursive CTE to the query.
+func (b InsertBuilder) WithRecursive(alias string, expr Sqlizer) InsertBuilder {
+	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: true, Expression: expr})
+}
+
+// WithCTE adds an arbitrary Sqlizer to the query.
+// The sqlizer will be sandwiched between the keyword WITH and, if there's more than one CTE, a comma.
+func (b InsertBuilder) WithCTE(cte Sqlizer) InsertBuilder {
+	return builder.Append(b, "CTEs", cte).(InsertBuilder)
+}
+
 // Into sets the INTO clause of the query.
 func (b InsertBuilder) Into(into string) InsertBuilder {
 	return builder.Set(b, "Into", into).(InsertBuilder)
@@ -266,6 +352,27 @@ func (b InsertBuilder) SuffixExpr(expr Sqlizer) InsertBuilder {
 	return builder.Append(b, "Suffixes", expr).(InsertBuilder)
 }
 
+// SuffixNamed adds an expression with named parameters bound from params to
+// the end of the query.
+//
+// See NamedExpr.
+func (b InsertBuilder) SuffixNamed(sql string, params interface{}) InsertBuilder {
+	return b.SuffixExpr(NamedExpr(sql, params))
+}
+
+// Returning adds RETURNING expressions to the query.
+//
+// Under a Dialect using ReturningOutput (SQL Server) they are rendered as an
+// OUTPUT clause, with plain column names qualified by the INSERTED pseudo table.
+func (b InsertBuilder) Returning(columns ...string) InsertBuilder {
+	return builder.Extend(b, "Returning", newReturningColumns(columns)).(InsertBuilder)
+}
+
+// ReturningExpr adds a RETURNING expression to the query.
+func (b InsertBuilder) ReturningExpr(expr Sqlizer) InsertBuilder {
+	return builder.Append(b, "Returning", expr).(InsertBuilder)
+}
+
 // SetMap set columns and values for insert builder from a map of column name and value
 // note that it will reset all previous columns and values was set if any
 func (b InsertBuilder) SetMap(clauses map[string]interface{}) InsertBuilder {
@@ -287,6 +394,100 @@ func (b InsertBuilder) SetMap(clauses map[string]interface{}) InsertBuilder {
 	return b
 }
 
+// SetStruct sets columns and values for insert builder from the fields of a
+// struct, or pointer to struct, mapped by their `db` tags like SetMap does for
+// a map. Columns are in field declaration order.
+//
+// Fields tagged readonly are skipped, as are fields tagged omitempty that hold
+// their zero value, e.g. an auto-increment `db:"id,pk,omitempty"`. opts
+// include or exclude further columns. Like SetMap, it resets all previous
+// columns and values.
+//
+// SetStruct panics if s is not a struct.
+func (b InsertBuilder) SetStruct(s interface{}, opts ...StructOption) InsertBuilder {
+	v := mustIndirectStruct(s, "SetStruct")
+
+	var cols []string
+	var vals []interface{}
+	for _, f := range writableFields(v.Type(), opts) {
+		val, zero := fieldValue(v, f)
+		if f.omitEmpty && zero {
+			continue
+		}
+		cols = append(cols, f.name)
+		vals = append(vals, val)
+	}
+
+	b = builder.Set(b, "Columns", cols).(InsertBuilder)
+	b = builder.Set(b, "Values", [][]interface{}{vals}).(InsertBuilder)
+
+	return b
+}
+
+// ValuesStructs sets columns and one row of values per element for insert
+// builder from a slice of structs, or pointers to structs, of the same type.
+//
+// Fields are selected as by SetStruct, except that a field tagged omitempty is
+// skipped only if it holds its zero value in every element, so that all rows
+// have the same columns. Like SetMap, it resets all previous columns and
+// values.
+//
+// Note that when an omitempty field is set in some elements only, its column
+// is kept and the zero value of the other elements is written as is: the
+// column default does not apply to them. Insert such rows separately, or use
+// SetStruct for each, if the default matters.
+//
+// ValuesStructs panics if structs is not a slice of structs of one type.
+func (b InsertBuilder) ValuesStructs(structs interface{}, opts ...StructOption) InsertBuilder {
+	sv := reflect.ValueOf(structs)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		panic(fmt.Sprintf("ValuesStructs: expected a slice of structs, not %T", structs))
+	}
+
+	rows := make([]reflect.Value, sv.Len())
+	for i := range rows {
+		rows[i] = mustIndirectStruct(sv.Index(i).Interface(), "ValuesStructs")
+		if rows[i].Type() != rows[0].Type() {
+			panic(fmt.Sprintf("ValuesStructs: mixed element types %s and %s", rows[0].Type(), rows[i].Type()))
+		}
+	}
+	if len(rows) == 0 {
+		return b
+	}
+
+	var fields []structField
+	for _, f := range writableFields(rows[0].Type(), opts) {
+		keep := !f.omitEmpty
+		for _, row := range rows {
+			if keep {
+				break
+			}
+			_, zero := fieldValue(row, f)
+			keep = !zero
+		}
+		if keep {
+			fields = append(fields, f)
+		}
+	}
+
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = f.name
+	}
+	values := make([][]interface{}, len(rows))
+	for r, row := range rows {
+		values[r] = make([]interface{}, len(fields))
+		for i, f := range fields {
+			values[r][i], _ = fieldValue(row, f)
+		}
+	}
+
+	b = builder.Set(b, "Columns", cols).(InsertBuilder)
+	b = builder.Set(b, "Values", values).(InsertBuilder)
+
+	return b
+}
+
 // Select set Select clause for insert query
 // If Values and Select are used, then Select has higher priority
 func (b InsertBuilder) Select(sb SelectBuilder) InsertBuilder {
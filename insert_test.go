@@ -110,3 +110,68 @@ func TestInsertBuilderReplace(t *testing.T) {
 
 	assert.Equal(t, expectedSQL, sql)
 }
+
+type insertStructUser struct {
+	ID        int    `db:"id,pk,omitempty"`
+	Name      string `db:"name"`
+	Email     string `db:"email,omitempty"`
+	CreatedAt string `db:"created_at,readonly"`
+	Ignored   int    `db:"-"`
+}
+
+func TestInsertBuilderSetStruct(t *testing.T) {
+	b := Insert("users").SetStruct(&insertStructUser{Name: "moe", CreatedAt: "now", Ignored: 1})
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) VALUES (?)", sql)
+	assert.Equal(t, []interface{}{"moe"}, args)
+
+	b = Insert("users").SetStruct(insertStructUser{ID: 7, Name: "larry", Email: "l@x"}, ExcludeColumns("email"))
+
+	sql, args, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{7, "larry"}, args)
+}
+
+func TestInsertBuilderValuesStructs(t *testing.T) {
+	users := []insertStructUser{
+		{Name: "moe"},
+		{Name: "larry", Email: "l@x"},
+	}
+	b := Insert("users").ValuesStructs(users)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	// email is set in one row only, so the zero value is written for the other.
+	assert.Equal(t, "INSERT INTO users (name,email) VALUES (?,?),(?,?)", sql)
+	assert.Equal(t, []interface{}{"moe", "", "larry", "l@x"}, args)
+
+	b = Insert("users").ValuesStructs([]*insertStructUser{{ID: 1, Name: "curly"}}, IncludeColumns("id", "name"))
+
+	sql, args, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{1, "curly"}, args)
+}
+
+func TestInsertBuilderStructShadowed(t *testing.T) {
+	v := structShadowModel{structShadowA: structShadowA{ID: 1, Name: "inner"}, Name: "outer"}
+
+	sql, args, err := Insert("users").SetStruct(v).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{1, "outer"}, args)
+
+	sql, args, err = Insert("users").ValuesStructs([]structShadowModel{v}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{1, "outer"}, args)
+}
+
+func TestInsertBuilderStructPanics(t *testing.T) {
+	assert.Panics(t, func() { Insert("users").SetStruct(1) })
+	assert.Panics(t, func() { Insert("users").ValuesStructs(insertStructUser{}) })
+	assert.Panics(t, func() { Insert("users").ValuesStructs([]interface{}{insertStructUser{}, 1}) })
+}
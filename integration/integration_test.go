@@ -3,10 +3,13 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -30,11 +33,13 @@ const (
 )
 
 var (
-	sb sqrl.StatementBuilderType
+	sb     sqrl.StatementBuilderType
+	testDB *sql.DB
+
+	driver, dataSource string
 )
 
 func TestMain(m *testing.M) {
-	var driver, dataSource string
 	flag.StringVar(&driver, "driver", "", "integration database driver")
 	flag.StringVar(&dataSource, "dataSource", "", "integration database data source")
 	flag.Parse()
@@ -70,6 +75,7 @@ func TestMain(m *testing.M) {
 		os.Exit(-3)
 	}
 
+	testDB = db
 	sb = sqrl.StatementBuilder.RunWith(db)
 
 	if driver == "postgres" {
@@ -130,6 +136,325 @@ func TestConj(t *testing.T) {
 func TestContext(t *testing.T) {
 	s := sb.Select("v").From("squirrel_integration")
 	ctx := context.Background()
-	_, err := s.QueryContext(ctx)
+	rows, err := s.QueryContext(ctx)
+	assert.NoError(t, err)
+	rows.Close()
+}
+
+type kv struct {
+	K int            `db:"k"`
+	V sql.NullString `db:"v"`
+}
+
+func TestQueryAll(t *testing.T) {
+	ctx := context.Background()
+	s := sb.Select("k", "v").From("squirrel_integration").OrderBy("k")
+
+	all, err := sqrl.QueryAll[kv](ctx, s)
+	assert.NoError(t, err)
+	assert.Len(t, all, 4)
+	assert.Equal(t, kv{K: 1, V: sql.NullString{String: "foo", Valid: true}}, all[0])
+
+	vals, err := sqrl.QueryAll[string](ctx, s.RemoveColumns().Columns("v"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "foo", "bar", "baz"}, vals)
+
+	_, err = sqrl.QueryAll[kv](ctx, s.Column("k + 1 AS k2"))
+	assert.IsType(t, &sqrl.ScanError{}, err)
+}
+
+func TestQueryOne(t *testing.T) {
+	ctx := context.Background()
+	s := sb.Select("k", "v").From("squirrel_integration")
+
+	row, err := sqrl.QueryOne[kv](ctx, s.Where(sqrl.Eq{"k": 3}))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", row.V.String)
+
+	_, err = sqrl.QueryOne[kv](ctx, s.Where(sqrl.Eq{"k": 5}))
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestRows(t *testing.T) {
+	ctx := context.Background()
+	s := sb.Select("v").From("squirrel_integration").OrderBy("k")
+
+	var vals []string
+	for row, err := range s.Rows(ctx) {
+		assert.NoError(t, err)
+		var v string
+		assert.NoError(t, row.Scan(&v))
+		vals = append(vals, v)
+		if len(vals) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"foo", "foo"}, vals)
+
+	var kvs []kv
+	for row, err := range sqrl.QueryRows[kv](ctx, s.Column("k").Where(sqrl.Gt{"k": 2})) {
+		assert.NoError(t, err)
+		kvs = append(kvs, row)
+	}
+	assert.Equal(t, []int{3, 4}, []int{kvs[0].K, kvs[1].K})
+
+	for _, err := range sqrl.QueryRows[kv](ctx, s) {
+		assert.IsType(t, &sqrl.ScanError{}, err)
+	}
+}
+
+func TestRowsReturning(t *testing.T) {
+	ctx := context.Background()
+
+	var keys []int
+	for row, err := range sb.Insert("squirrel_integration").Columns("k", "v").
+		Values(10, "ten").Values(11, "eleven").Returning("k").Rows(ctx) {
+		if err != nil {
+			t.Skipf("RETURNING not supported: %v", err)
+		}
+		var k int
+		assert.NoError(t, row.Scan(&k))
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []int{10, 11}, keys)
+
+	deleted, err := sqrl.QueryAll[string](ctx, sb.Delete("squirrel_integration").Where(sqrl.GtOrEq{"k": 10}).Returning("v"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ten", "eleven"}, deleted)
+}
+
+func countKeys(t *testing.T, runner sqrl.BaseRunner, min int) int {
+	var n int
+	err := sb.Select("COUNT(*)").From("squirrel_integration").Where(sqrl.GtOrEq{"k": min}).RunWith(runner).Scan(&n)
+	assert.NoError(t, err)
+	return n
+}
+
+func TestInTx(t *testing.T) {
+	ctx := context.Background()
+	db := testDB
+	defer sb.Delete("squirrel_integration").Where(sqrl.GtOrEq{"k": 100}).Exec()
+
+	err := sqrl.InTx(ctx, db, nil, func(tx sqrl.RunnerContext) error {
+		_, err := sb.Insert("squirrel_integration").Values(100, "tx").RunWith(tx).ExecContext(ctx)
+		return err
+	})
 	assert.NoError(t, err)
+	assert.Equal(t, 1, countKeys(t, db, 100))
+
+	errRollback := errors.New("rollback")
+	err = sqrl.InTx(ctx, db, nil, func(tx sqrl.RunnerContext) error {
+		_, err := sb.Insert("squirrel_integration").Values(101, "tx").RunWith(tx).ExecContext(ctx)
+		assert.NoError(t, err)
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+	assert.Equal(t, 1, countKeys(t, db, 100))
+}
+
+func TestInTxSavepoint(t *testing.T) {
+	ctx := context.Background()
+	db := testDB
+	defer sb.Delete("squirrel_integration").Where(sqrl.GtOrEq{"k": 100}).Exec()
+
+	err := sqrl.InTx(ctx, db, nil, func(tx sqrl.RunnerContext) error {
+		insert := sb.Insert("squirrel_integration").RunWith(tx)
+		if _, err := insert.Values(102, "outer").ExecContext(ctx); err != nil {
+			return err
+		}
+		nestedErr := sqrl.InTx(ctx, tx, nil, func(tx sqrl.RunnerContext) error {
+			_, err := insert.Values(103, "inner").RunWith(tx).ExecContext(ctx)
+			assert.NoError(t, err)
+			return errors.New("undo inner")
+		})
+		assert.Error(t, nestedErr)
+		return sqrl.InTx(ctx, tx, nil, func(tx sqrl.RunnerContext) error {
+			_, err := insert.Values(104, "kept").RunWith(tx).ExecContext(ctx)
+			return err
+		})
+	})
+	assert.NoError(t, err)
+
+	keys, err := sqrl.QueryAll[int](ctx, sb.Select("k").From("squirrel_integration").Where(sqrl.GtOrEq{"k": 100}).OrderBy("k"))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{102, 104}, keys)
+}
+
+func TestInTxRetry(t *testing.T) {
+	ctx := context.Background()
+	db := testDB
+	defer sb.Delete("squirrel_integration").Where(sqrl.GtOrEq{"k": 100}).Exec()
+
+	errConflict := errors.New("conflict")
+	opts := &sqrl.TxOptions{
+		Retryable: func(err error) bool { return err == errConflict },
+		Backoff:   func(int) time.Duration { return time.Millisecond },
+	}
+
+	attempts := 0
+	err := sqrl.InTx(ctx, db, opts, func(tx sqrl.RunnerContext) error {
+		attempts++
+		if _, err := sb.Insert("squirrel_integration").Values(105, "retry").RunWith(tx).ExecContext(ctx); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errConflict
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 1, countKeys(t, db, 100))
+
+	attempts = 0
+	err = sqrl.InTx(ctx, db, opts, func(tx sqrl.RunnerContext) error {
+		attempts++
+		return errConflict
+	})
+	assert.Equal(t, errConflict, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestStmtCacheEvict(t *testing.T) {
+	sc := sqrl.NewStmtCache(testDB, sqrl.StmtCacheCapacity(1))
+	defer sc.Clear()
+
+	first, err := sc.Prepare("SELECT 1")
+	assert.NoError(t, err)
+	_, err = sc.Prepare("SELECT 2")
+	assert.NoError(t, err)
+
+	_, err = first.Exec()
+	assert.Error(t, err, "evicted statement should be closed")
+	assert.Equal(t, uint64(1), sc.Stats().Evictions)
+}
+
+func TestStmtCacheTx(t *testing.T) {
+	ctx := context.Background()
+	sc := sqrl.NewStmtCache(testDB)
+	defer sc.Clear()
+	defer sb.Delete("squirrel_integration").Where(sqrl.GtOrEq{"k": 100}).Exec()
+
+	insert := sb.Insert("squirrel_integration").Columns("k", "v")
+
+	// Prepare outside of the transactions: each sqlite :memory: connection
+	// has its own database, so the cache cannot prepare on a second one.
+	query, _, err := insert.Values(0, "").ToSql()
+	assert.NoError(t, err)
+	_, err = sc.PrepareContext(ctx, query)
+	assert.NoError(t, err)
+
+	for _, k := range []int{106, 107} {
+		err := sqrl.InTx(ctx, sc, nil, func(tx sqrl.RunnerContext) error {
+			_, err := insert.Values(k, "cached").RunWith(tx).ExecContext(ctx)
+			return err
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, countKeys(t, testDB, 100))
+
+	stats := sc.Stats()
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(2), stats.Hits)
+}
+
+func TestStmtCacheProxyBeginTx(t *testing.T) {
+	ctx := context.Background()
+	proxy := sqrl.NewStmtCacheProxy(testDB)
+
+	tx, err := proxy.BeginTx(ctx, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tx.Rollback()
+
+	var n int
+	assert.NoError(t, tx.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	assert.Equal(t, 1, n)
+	assert.NoError(t, tx.Commit())
+}
+
+func TestStmtCacheInvalidation(t *testing.T) {
+	sc := sqrl.NewStmtCache(testDB)
+	defer sc.Clear()
+	query := "SELECT COUNT(*) FROM squirrel_integration"
+
+	stmt, err := sc.Prepare(query)
+	assert.NoError(t, err)
+	stmt.Close()
+
+	var n int
+	assert.NoError(t, sc.QueryRow(query).Scan(&n))
+	assert.Equal(t, 4, n)
+
+	stats := sc.Stats()
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Invalidations)
+}
+
+func TestStmtCacheConcurrentEvict(t *testing.T) {
+	// Use a database handle of its own, as each of the connections opened by
+	// the concurrent queries to a sqlite :memory: database gets an empty one.
+	db, err := sql.Open(driver, dataSource)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+
+	// Never invalidate on errors, so that only the closed statement handling
+	// can retry a statement evicted by another goroutine.
+	sc := sqrl.NewStmtCache(db,
+		sqrl.StmtCacheCapacity(1),
+		sqrl.StmtCacheInvalidateOn(func(error) bool { return false }))
+	defer sc.Clear()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				want := (i + j) % 4
+				var n int
+				err := sc.QueryRow(fmt.Sprintf("SELECT %d", want)).Scan(&n)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, want, n)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLikeEscape(t *testing.T) {
+	s := sb.Select("v").From("squirrel_integration").OrderBy("k")
+	assertVals(t, s.Where(sqrl.Like{"v": sqrl.StartsWith("ba")}), "bar", "baz")
+	assertVals(t, s.Where(sqrl.Like{"v": sqrl.Contains("_")}))
+	assertVals(t, s.Where(sqrl.Like{"v": sqrl.Contains("!")}))
+	assertVals(t, s.Where(sqrl.Like{"v": sqrl.EndsWith("o")}), "foo", "foo")
+}
+
+type countingHook struct{ before, after *int }
+
+func (h countingHook) Before(ctx context.Context, query string, args []interface{}) context.Context {
+	*h.before++
+	return ctx
+}
+
+func (h countingHook) After(ctx context.Context, query string, args []interface{}, err error, elapsed time.Duration) {
+	*h.after++
+}
+
+func TestWrapHooksDB(t *testing.T) {
+	ctx := context.Background()
+	var before, after int
+	db := sqrl.WrapHooks(testDB, countingHook{&before, &after})
+
+	var n int
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM squirrel_integration").Scan(&n))
+	assert.Equal(t, 4, n)
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM squirrel_integration").Scan(&n))
+	assert.Equal(t, 2, before)
+	assert.Equal(t, 2, after)
 }
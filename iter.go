@@ -0,0 +1,115 @@
+package squirrel
+
+import (
+	"context"
+	"iter"
+)
+
+// QueryRows runs q with QueryContext and returns an iterator over its rows,
+// each scanned into a T as by ScanAll:
+//
+//	for user, err := range QueryRows[User](ctx, sb) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Errors from the query, the scan or rows.Err are yielded with a zero T and end
+// the iteration. The rows are closed when the iteration ends, including when
+// the loop breaks early.
+func QueryRows[T any](ctx context.Context, q QueryContexter) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := q.QueryContext(ctx)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		scan, err := newRowScanner[T](rows)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		for rows.Next() {
+			var dest T
+			if err := scan(rows, &dest); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(dest, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// queryRowScanners runs q with QueryContext and returns an iterator yielding a
+// RowScanner for each row. See SelectBuilder.Rows.
+func queryRowScanners(ctx context.Context, q QueryContexter) iter.Seq2[RowScanner, error] {
+	return func(yield func(RowScanner, error) bool) {
+		rows, err := q.QueryContext(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if !yield(rows, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Rows builds and QueryContexts the query with the Runner set by RunWith and
+// returns an iterator over its rows:
+//
+//	for row, err := range sb.Rows(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		err = row.Scan(&id, &name)
+//		...
+//	}
+//
+// Errors from the query or rows.Err are yielded with a nil RowScanner and end
+// the iteration. The rows are closed when the iteration ends, including when
+// the loop breaks early. The RowScanner is only valid until the next
+// iteration. To scan into structs use QueryRows.
+func (b SelectBuilder) Rows(ctx context.Context) iter.Seq2[RowScanner, error] {
+	return queryRowScanners(ctx, b)
+}
+
+// Rows builds and QueryContexts the query, usually one with Returning, and
+// returns an iterator over its rows.
+//
+// See SelectBuilder.Rows.
+func (b InsertBuilder) Rows(ctx context.Context) iter.Seq2[RowScanner, error] {
+	return queryRowScanners(ctx, b)
+}
+
+// Rows builds and QueryContexts the query, usually one with Returning, and
+// returns an iterator over its rows.
+//
+// See SelectBuilder.Rows.
+func (b UpdateBuilder) Rows(ctx context.Context) iter.Seq2[RowScanner, error] {
+	return queryRowScanners(ctx, b)
+}
+
+// Rows builds and QueryContexts the query, usually one with Returning, and
+// returns an iterator over its rows.
+//
+// See SelectBuilder.Rows.
+func (b DeleteBuilder) Rows(ctx context.Context) iter.Seq2[RowScanner, error] {
+	return queryRowScanners(ctx, b)
+}
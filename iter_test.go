@@ -0,0 +1,24 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowsNoRunner(t *testing.T) {
+	calls := 0
+	for row, err := range Select("id").From("users").Rows(ctx) {
+		calls++
+		assert.Nil(t, row)
+		assert.Equal(t, RunnerNotSet, err)
+	}
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	for _, err := range QueryRows[int](ctx, Delete("users").Returning("id")) {
+		calls++
+		assert.Equal(t, RunnerNotSet, err)
+	}
+	assert.Equal(t, 1, calls)
+}
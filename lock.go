@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// LockStrength is the strength of a row locking clause, see SelectBuilder.For.
+type LockStrength string
+
+const (
+	// LockUpdate locks rows with FOR UPDATE.
+	LockUpdate LockStrength = "UPDATE"
+
+	// LockNoKeyUpdate locks rows with FOR NO KEY UPDATE (PostgreSQL only).
+	LockNoKeyUpdate LockStrength = "NO KEY UPDATE"
+
+	// LockShare locks rows with FOR SHARE.
+	LockShare LockStrength = "SHARE"
+
+	// LockKeyShare locks rows with FOR KEY SHARE (PostgreSQL only).
+	LockKeyShare LockStrength = "KEY SHARE"
+)
+
+func (d *selectData) lockToSql() (string, error) {
+	// Few databases accept a locking clause on a parenthesized operand of a
+	// compound query, and none on an unparenthesized one.
+	compoundLock := d.ParenCompounds && d.Dialect != nil && d.Dialect.SupportsCompoundRowLock()
+	if !compoundLock {
+		for _, c := range d.Compounds {
+			p, ok := c.(compoundSelectPart)
+			if !ok {
+				continue
+			}
+			if lock, _ := builder.Get(p.query, "Lock"); lock != nil && lock != LockStrength("") {
+				return "", fmt.Errorf("FOR %s is not allowed with UNION, INTERSECT or EXCEPT", lock)
+			}
+		}
+	}
+
+	if d.Lock == "" {
+		if len(d.LockOf) > 0 || d.LockWait != "" {
+			return "", errors.New("the Of, NoWait and SkipLocked options require For")
+		}
+		return "", nil
+	}
+	if d.Dialect != nil && !d.Dialect.SupportsRowLock(d.Lock) {
+		return "", fmt.Errorf("dialect %s does not support FOR %s", d.Dialect.Name(), d.Lock)
+	}
+	if len(d.Compounds) > 0 && !compoundLock {
+		return "", fmt.Errorf("FOR %s is not allowed with UNION, INTERSECT or EXCEPT", d.Lock)
+	}
+
+	sql := "FOR " + string(d.Lock)
+	if len(d.LockOf) > 0 {
+		sql += " OF " + strings.Join(d.LockOf, ", ")
+	}
+	if d.LockWait != "" {
+		sql += " " + d.LockWait
+	}
+	return sql, nil
+}
+
+// For adds a row locking clause, e.g. FOR UPDATE, to the end of the query,
+// after LIMIT and OFFSET.
+//
+// Ex:
+//
+//	Select("id").From("jobs").Where("state = ?", "queued").Limit(10).
+//		For(LockUpdate).SkipLocked()
+func (b SelectBuilder) For(strength LockStrength) SelectBuilder {
+	return builder.Set(b, "Lock", strength).(SelectBuilder)
+}
+
+// Of restricts the row locking clause set by For to rows of the given tables.
+func (b SelectBuilder) Of(tables ...string) SelectBuilder {
+	return builder.Extend(b, "LockOf", tables).(SelectBuilder)
+}
+
+// SkipLocked makes the row locking clause set by For skip rows that are
+// already locked instead of waiting for them.
+func (b SelectBuilder) SkipLocked() SelectBuilder {
+	return builder.Set(b, "LockWait", "SKIP LOCKED").(SelectBuilder)
+}
+
+// NoWait makes the row locking clause set by For fail instead of waiting for
+// rows that are already locked.
+func (b SelectBuilder) NoWait() SelectBuilder {
+	return builder.Set(b, "LockWait", "NOWAIT").(SelectBuilder)
+}
@@ -0,0 +1,100 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderForUpdateSkipLocked(t *testing.T) {
+	b := Select("id").
+		From("jobs").
+		Where("state = ?", "queued").
+		OrderBy("id").
+		Limit(10).
+		Offset(5).
+		For(LockUpdate).
+		SkipLocked().
+		Suffix("/* worker */")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id FROM jobs WHERE state = ? ORDER BY id LIMIT 10 OFFSET 5 " +
+		"FOR UPDATE SKIP LOCKED /* worker */"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{"queued"}, args)
+}
+
+func TestSelectBuilderForStrengths(t *testing.T) {
+	b := Select("*").From("jobs j").Join("runs r ON r.job_id = j.id")
+
+	sql, _, err := b.For(LockNoKeyUpdate).Of("j", "r").NoWait().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs j JOIN runs r ON r.job_id = j.id FOR NO KEY UPDATE OF j, r NOWAIT", sql)
+
+	sql, _, err = b.For(LockShare).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs j JOIN runs r ON r.job_id = j.id FOR SHARE", sql)
+
+	sql, _, err = b.For(LockKeyShare).Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM jobs j JOIN runs r ON r.job_id = j.id FOR KEY SHARE", sql)
+}
+
+func TestSelectBuilderForDialects(t *testing.T) {
+	b := Select("id").From("jobs").Limit(1)
+
+	sql, _, err := b.For(LockShare).SkipLocked().Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM jobs LIMIT 1 FOR SHARE SKIP LOCKED", sql)
+
+	_, _, err = b.For(LockKeyShare).Dialect(MySQL).ToSql()
+	assert.EqualError(t, err, "dialect mysql does not support FOR KEY SHARE")
+
+	_, _, err = b.For(LockUpdate).Dialect(SQLite).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = b.For(LockUpdate).Dialect(SQLServer).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = b.For(LockShare).Dialect(Oracle).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderForCompounds(t *testing.T) {
+	b := Select("id").From("a").Limit(1).For(LockUpdate).
+		UnionSelect(Select("id").From("b"))
+
+	_, _, err := b.ToSql()
+	assert.EqualError(t, err, "FOR UPDATE is not allowed with UNION, INTERSECT or EXCEPT")
+
+	_, _, err = b.ParenthesizeCompounds().Dialect(Postgres).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := b.ParenthesizeCompounds().Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a LIMIT 1 FOR UPDATE) UNION (SELECT id FROM b)", sql)
+
+	sql, _, err = b.ParenthesizeCompounds().Dialect(MariaDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a LIMIT 1 FOR UPDATE) UNION (SELECT id FROM b)", sql)
+
+	// Operands are checked as well.
+	b = Select("id").From("a").UnionSelect(Select("id").From("b").Limit(1).For(LockUpdate))
+
+	_, _, err = b.ToSql()
+	assert.EqualError(t, err, "FOR UPDATE is not allowed with UNION, INTERSECT or EXCEPT")
+
+	_, _, err = b.ParenthesizeCompounds().Dialect(Postgres).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err = b.ParenthesizeCompounds().Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a) UNION (SELECT id FROM b LIMIT 1 FOR UPDATE)", sql)
+}
+
+func TestSelectBuilderLockOptionsWithoutFor(t *testing.T) {
+	_, _, err := Select("id").From("jobs").SkipLocked().ToSql()
+	assert.EqualError(t, err, "the Of, NoWait and SkipLocked options require For")
+}
@@ -0,0 +1,459 @@
+package squirrel
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+type mergeData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           BaseRunner
+	Prefixes          []Sqlizer
+	CTEs              []Sqlizer
+	Into              string
+	Using             Sqlizer
+	OnParts           []Sqlizer
+	WhenParts         []mergeWhenPart
+	Suffixes          []Sqlizer
+	Comment           map[string]string
+	CommentPrefix     bool
+}
+
+// mergeWhenPart is either a WHEN condition or the action following it; they
+// are appended separately by the builder and paired up by ToSql.
+type mergeWhenPart struct {
+	when    string
+	cond    Sqlizer
+	action  string
+	set     []setClause
+	columns []string
+	values  []interface{}
+}
+
+func (d *mergeData) Exec() (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return ExecWith(d.RunWith, d)
+}
+
+func (d *mergeData) Query() (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, RunnerNotSet
+	}
+	return QueryWith(d.RunWith, d)
+}
+
+func (d *mergeData) QueryRow() RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: RunnerNotSet}
+	}
+	queryRower, ok := d.RunWith.(QueryRower)
+	if !ok {
+		return &Row{err: RunnerNotQueryRunner}
+	}
+	return QueryRowWith(queryRower, d)
+}
+
+func (d *mergeData) ToSql() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = d.toSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr = withComment(sqlStr, d.Comment, d.CommentPrefix)
+
+	// SQL Server requires MERGE statements to be terminated.
+	if d.Dialect == SQLServer {
+		sqlStr += ";"
+	}
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	return
+}
+
+func (d *mergeData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
+	if len(d.Into) == 0 {
+		err = errors.New("merge statements must specify a target table")
+		return
+	}
+	if d.Using == nil {
+		err = errors.New("merge statements must specify a USING source")
+		return
+	}
+	if len(d.OnParts) == 0 {
+		err = errors.New("merge statements must have an ON clause")
+		return
+	}
+	if len(d.WhenParts) == 0 {
+		err = errors.New("merge statements must have at least one WHEN clause")
+		return
+	}
+
+	sql := &bytes.Buffer{}
+
+	if len(d.Prefixes) > 0 {
+		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		if err != nil {
+			return
+		}
+
+		sql.WriteString(" ")
+	}
+
+	if len(d.CTEs) > 0 {
+		sql.WriteString("WITH ")
+		args, err = appendToSql(applyDialect(d.CTEs, d.Dialect), sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sql.WriteString(" ")
+	}
+
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(d.Into)
+
+	sql.WriteString(" USING ")
+	args, err = appendToSql([]Sqlizer{adaptToDialect(d.Using, d.Dialect)}, sql, "", args)
+	if err != nil {
+		return
+	}
+
+	sql.WriteString(" ON ")
+	args, err = appendToSql(applyDialect(d.OnParts, d.Dialect), sql, " AND ", args)
+	if err != nil {
+		return
+	}
+
+	args, err = d.appendWhenPartsToSql(sql, args)
+	if err != nil {
+		return
+	}
+
+	if len(d.Suffixes) > 0 {
+		sql.WriteString(" ")
+		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		if err != nil {
+			return
+		}
+	}
+
+	sqlStr = sql.String()
+	return
+}
+
+func (d *mergeData) appendWhenPartsToSql(sql *bytes.Buffer, args []interface{}) ([]interface{}, error) {
+	var when *mergeWhenPart
+	for i := range d.WhenParts {
+		p := &d.WhenParts[i]
+		if p.when != "" {
+			if when != nil {
+				return nil, fmt.Errorf("WHEN %s clause requires an action", when.when)
+			}
+			when = p
+			continue
+		}
+		if when == nil {
+			return nil, fmt.Errorf("%s action must follow a WHEN clause", p.action)
+		}
+
+		sql.WriteString(" WHEN ")
+		sql.WriteString(when.when)
+		if when.cond != nil {
+			condSql, condArgs, err := nestedToSql(adaptToDialect(when.cond, d.Dialect))
+			if err != nil {
+				return nil, err
+			}
+			if condSql != "" {
+				sql.WriteString(" AND ")
+				sql.WriteString(condSql)
+				args = append(args, condArgs...)
+			}
+		}
+		sql.WriteString(" THEN ")
+
+		insert := p.action == "INSERT"
+		if p.action != "DO NOTHING" && insert != (when.when == "NOT MATCHED") {
+			return nil, fmt.Errorf("%s action is not allowed in a WHEN %s clause", p.action, when.when)
+		}
+		if p.action == "DO NOTHING" && d.Dialect != nil && !d.Dialect.SupportsMergeDoNothing() {
+			return nil, fmt.Errorf("dialect %s does not support DO NOTHING in MERGE statements", d.Dialect.Name())
+		}
+
+		switch p.action {
+		case "UPDATE":
+			setSql, setArgs, err := setClausesToSql(p.set, func(vs Sqlizer) Sqlizer {
+				return adaptToDialect(vs, d.Dialect)
+			})
+			if err != nil {
+				return nil, err
+			}
+			sql.WriteString("UPDATE SET ")
+			sql.WriteString(setSql)
+			args = append(args, setArgs...)
+		case "INSERT":
+			sql.WriteString("INSERT ")
+			if len(p.columns) > 0 {
+				sql.WriteString("(")
+				sql.WriteString(strings.Join(p.columns, ","))
+				sql.WriteString(") ")
+			}
+			valueStrings := make([]string, len(p.values))
+			for v, val := range p.values {
+				if vs, ok := val.(Sqlizer); ok {
+					vsql, vargs, err := nestedToSql(adaptToDialect(vs, d.Dialect))
+					if err != nil {
+						return nil, err
+					}
+					valueStrings[v] = vsql
+					args = append(args, vargs...)
+				} else {
+					valueStrings[v] = "?"
+					args = append(args, val)
+				}
+			}
+			sql.WriteString("VALUES (")
+			sql.WriteString(strings.Join(valueStrings, ","))
+			sql.WriteString(")")
+		default:
+			sql.WriteString(p.action)
+		}
+		when = nil
+	}
+	if when != nil {
+		return nil, fmt.Errorf("WHEN %s clause requires an action", when.when)
+	}
+	return args, nil
+}
+
+// Builder
+
+// MergeBuilder builds SQL MERGE statements.
+type MergeBuilder builder.Builder
+
+func init() {
+	builder.Register(MergeBuilder{}, mergeData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b MergeBuilder) PlaceholderFormat(f PlaceholderFormat) MergeBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(MergeBuilder)
+}
+
+// Dialect sets the Dialect used to render the query, along with its default
+// PlaceholderFormat.
+func (b MergeBuilder) Dialect(d Dialect) MergeBuilder {
+	return setDialect(b, d).(MergeBuilder)
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
+func (b MergeBuilder) RunWith(runner BaseRunner) MergeBuilder {
+	return setRunWith(b, runner).(MergeBuilder)
+}
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b MergeBuilder) Exec() (sql.Result, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.Exec()
+}
+
+// Query builds and Querys the query with the Runner set by RunWith.
+func (b MergeBuilder) Query() (*sql.Rows, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.Query()
+}
+
+// QueryRow builds and QueryRows the query with the Runner set by RunWith.
+func (b MergeBuilder) QueryRow() RowScanner {
+	data := builder.GetStruct(b).(mergeData)
+	return data.QueryRow()
+}
+
+// Scan is a shortcut for QueryRow().Scan.
+func (b MergeBuilder) Scan(dest ...interface{}) error {
+	return b.QueryRow().Scan(dest...)
+}
+
+// SQL methods
+
+// ToSql builds the query into a SQL string and bound args.
+func (b MergeBuilder) ToSql() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ToSql()
+}
+
+func (b MergeBuilder) toSqlRaw() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.toSqlRaw()
+}
+
+// MustSql builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b MergeBuilder) MustSql() (string, []interface{}) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// Prefix adds an expression to the beginning of the query
+func (b MergeBuilder) Prefix(sql string, args ...interface{}) MergeBuilder {
+	return b.PrefixExpr(Expr(sql, args...))
+}
+
+// PrefixExpr adds an expression to the very beginning of the query
+func (b MergeBuilder) PrefixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Prefixes", expr).(MergeBuilder)
+}
+
+// With adds a non-recursive CTE to the query.
+func (b MergeBuilder) With(alias string, expr Sqlizer) MergeBuilder {
+	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: false, Expression: expr})
+}
+
+// WithCTE adds an arbitrary Sqlizer to the query.
+// The sqlizer will be sandwiched between the keyword WITH and, if there's more than one CTE, a comma.
+func (b MergeBuilder) WithCTE(cte Sqlizer) MergeBuilder {
+	return builder.Append(b, "CTEs", cte).(MergeBuilder)
+}
+
+// Into sets the target table of the query, optionally with an alias
+// (e.g. "customers AS c").
+func (b MergeBuilder) Into(into string) MergeBuilder {
+	return builder.Set(b, "Into", into).(MergeBuilder)
+}
+
+// Using sets the USING source of the query. source may be a table name or a
+// Sqlizer such as a SelectBuilder, which is wrapped in parentheses. alias may
+// be empty for a table name but is required for a Sqlizer.
+func (b MergeBuilder) Using(source interface{}, alias string) MergeBuilder {
+	var using Sqlizer
+	switch s := source.(type) {
+	case string:
+		if alias != "" {
+			s = s + " AS " + alias
+		}
+		using = newPart(s)
+	case Sqlizer:
+		if sb, ok := s.(SelectBuilder); ok {
+			// Prevent misnumbered parameters in nested selects (#183).
+			s = sb.PlaceholderFormat(Question)
+		}
+		using = mergeUsingPart{source: s, alias: alias}
+	default:
+		using = newPart(source)
+	}
+	return builder.Set(b, "Using", using).(MergeBuilder)
+}
+
+// mergeUsingPart is a subquery source of a MERGE statement.
+type mergeUsingPart struct {
+	source Sqlizer
+	alias  string
+}
+
+func (p mergeUsingPart) ToSql() (string, []interface{}, error) {
+	if p.alias == "" {
+		return "", nil, fmt.Errorf("merge statements with a subquery source must have an alias")
+	}
+	sql, args, err := nestedToSql(p.source)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("(%s) AS %s", sql, p.alias), args, nil
+}
+
+func (p mergeUsingPart) withDialect(d Dialect) Sqlizer {
+	p.source = adaptToDialect(p.source, d)
+	return p
+}
+
+// On adds an expression to the ON clause of the query.
+//
+// Expressions are ANDed together. See SelectBuilder.Where for the accepted
+// types of pred.
+func (b MergeBuilder) On(pred interface{}, args ...interface{}) MergeBuilder {
+	return builder.Append(b, "OnParts", newWherePart(pred, args...)).(MergeBuilder)
+}
+
+// WhenMatched starts a WHEN MATCHED clause, which must be followed by Update,
+// Delete or DoNothing. An optional condition, given like the arguments of
+// Case, is ANDed to the clause.
+func (b MergeBuilder) WhenMatched(cond ...interface{}) MergeBuilder {
+	return b.when("MATCHED", cond)
+}
+
+// WhenNotMatched starts a WHEN NOT MATCHED clause, which must be followed by
+// Insert or DoNothing. An optional condition, given like the arguments of
+// Case, is ANDed to the clause.
+func (b MergeBuilder) WhenNotMatched(cond ...interface{}) MergeBuilder {
+	return b.when("NOT MATCHED", cond)
+}
+
+// WhenNotMatchedBySource starts a WHEN NOT MATCHED BY SOURCE clause (SQL
+// Server and PostgreSQL 17+), which must be followed by Update, Delete or
+// DoNothing.
+func (b MergeBuilder) WhenNotMatchedBySource(cond ...interface{}) MergeBuilder {
+	return b.when("NOT MATCHED BY SOURCE", cond)
+}
+
+func (b MergeBuilder) when(when string, cond []interface{}) MergeBuilder {
+	part := mergeWhenPart{when: when}
+	if len(cond) > 0 {
+		part.cond = newWherePart(cond[0], cond[1:]...)
+	}
+	return builder.Append(b, "WhenParts", part).(MergeBuilder)
+}
+
+// Update sets the action of the current WHEN clause to UPDATE SET.
+//
+// set may be a map[string]interface{} (or Eq) of columns to values, which is
+// rendered in sorted column order like UpdateBuilder.SetMap, or a Sqlizer
+// holding the whole SET list.
+func (b MergeBuilder) Update(set interface{}) MergeBuilder {
+	part := mergeWhenPart{action: "UPDATE"}
+	for _, c := range newSetClauses(set) {
+		part.set = append(part.set, c.(setClause))
+	}
+	return builder.Append(b, "WhenParts", part).(MergeBuilder)
+}
+
+// Delete sets the action of the current WHEN clause to DELETE.
+func (b MergeBuilder) Delete() MergeBuilder {
+	return builder.Append(b, "WhenParts", mergeWhenPart{action: "DELETE"}).(MergeBuilder)
+}
+
+// Insert sets the action of the current WHEN NOT MATCHED clause to INSERT.
+// values may be Sqlizers, e.g. Expr("src.name").
+func (b MergeBuilder) Insert(columns []string, values ...interface{}) MergeBuilder {
+	part := mergeWhenPart{action: "INSERT", columns: columns, values: values}
+	return builder.Append(b, "WhenParts", part).(MergeBuilder)
+}
+
+// DoNothing sets the action of the current WHEN clause to DO NOTHING
+// (PostgreSQL only; ToSql returns an error under other Dialects).
+func (b MergeBuilder) DoNothing() MergeBuilder {
+	return builder.Append(b, "WhenParts", mergeWhenPart{action: "DO NOTHING"}).(MergeBuilder)
+}
+
+// Suffix adds an expression to the end of the query
+func (b MergeBuilder) Suffix(sql string, args ...interface{}) MergeBuilder {
+	return b.SuffixExpr(Expr(sql, args...))
+}
+
+// SuffixExpr adds an expression to the end of the query
+func (b MergeBuilder) SuffixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Suffixes", expr).(MergeBuilder)
+}
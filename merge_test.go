@@ -0,0 +1,159 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBuilderToSql(t *testing.T) {
+	src := Select("id", "name").From("staging").Where(Eq{"batch": 7})
+	b := Merge("customers AS c").
+		Prefix("/* sync */").
+		Using(src, "s").
+		On("c.id = s.id").
+		WhenMatched("s.deleted = ?", true).Delete().
+		WhenMatched().Update(map[string]interface{}{
+		"name":    Expr("s.name"),
+		"version": Expr("c.version + ?", 1),
+	}).
+		WhenNotMatched().Insert([]string{"id", "name", "source"}, Expr("s.id"), Expr("s.name"), "sync").
+		Suffix("RETURNING ?", 5)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSQL := "/* sync */ " +
+		"MERGE INTO customers AS c " +
+		"USING (SELECT id, name FROM staging WHERE batch = ?) AS s ON c.id = s.id " +
+		"WHEN MATCHED AND s.deleted = ? THEN DELETE " +
+		"WHEN MATCHED THEN UPDATE SET name = s.name, version = c.version + ? " +
+		"WHEN NOT MATCHED THEN INSERT (id,name,source) VALUES (s.id,s.name,?) " +
+		"RETURNING ?"
+	assert.Equal(t, expectedSQL, sql)
+
+	expectedArgs := []interface{}{7, true, 1, "sync", 5}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestMergeBuilderToSqlErr(t *testing.T) {
+	_, _, err := Merge("").Using("s", "").On("t.id = s.id").WhenMatched().Delete().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").On("t.id = s.id").WhenMatched().Delete().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s", "").WhenMatched().Delete().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s", "").On("t.id = s.id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().ToSql()
+	assert.EqualError(t, err, "WHEN MATCHED clause requires an action")
+
+	_, _, err = Merge("t").Using("s", "").On("t.id = s.id").Delete().ToSql()
+	assert.EqualError(t, err, "DELETE action must follow a WHEN clause")
+
+	_, _, err = Merge("t").Using("s", "").On("t.id = s.id").WhenNotMatched().Delete().ToSql()
+	assert.EqualError(t, err, "DELETE action is not allowed in a WHEN NOT MATCHED clause")
+
+	_, _, err = Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().Insert(nil, 1).ToSql()
+	assert.EqualError(t, err, "INSERT action is not allowed in a WHEN MATCHED clause")
+
+	_, _, err = Merge("t").Using(Select("a").From("s"), "").On("t.a = s.a").WhenMatched().Delete().ToSql()
+	assert.EqualError(t, err, "merge statements with a subquery source must have an alias")
+}
+
+func TestMergeBuilderUsingSqlizer(t *testing.T) {
+	sql, args, err := Merge("t").
+		Using(Expr("VALUES (?, ?)", 1, 2), "s(a, b)").
+		On("t.a = s.a").
+		WhenMatched().Delete().
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING (VALUES (?, ?)) AS s(a, b) ON t.a = s.a WHEN MATCHED THEN DELETE", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestMergeBuilderMustSql(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestMergeBuilderMustSql should have panicked!")
+		}
+	}()
+	Merge("").MustSql()
+}
+
+func TestMergeBuilderPlaceholders(t *testing.T) {
+	b := Merge("t").
+		Using(Select("id").From("s").Where("id > ?", 1), "s").
+		On("t.id = s.id").
+		WhenNotMatched("s.id < ?", 10).Insert([]string{"id"}, Expr("s.id"))
+
+	sql, _, _ := b.PlaceholderFormat(Question).ToSql()
+	assert.Equal(t, "MERGE INTO t USING (SELECT id FROM s WHERE id > ?) AS s ON t.id = s.id "+
+		"WHEN NOT MATCHED AND s.id < ? THEN INSERT (id) VALUES (s.id)", sql)
+
+	sql, _, _ = b.PlaceholderFormat(Dollar).ToSql()
+	assert.Equal(t, "MERGE INTO t USING (SELECT id FROM s WHERE id > $1) AS s ON t.id = s.id "+
+		"WHEN NOT MATCHED AND s.id < $2 THEN INSERT (id) VALUES (s.id)", sql)
+}
+
+func TestMergeBuilderDialect(t *testing.T) {
+	b := Merge("t").
+		Using("s", "src").
+		On("t.id = src.id").
+		WhenMatched().Update(Eq{"v": 1}).
+		WhenNotMatchedBySource().Delete()
+
+	sql, _, err := b.Dialect(SQLServer).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s AS src ON t.id = src.id "+
+		"WHEN MATCHED THEN UPDATE SET v = @p1 WHEN NOT MATCHED BY SOURCE THEN DELETE;", sql)
+
+	sql, _, err = b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s AS src ON t.id = src.id "+
+		"WHEN MATCHED THEN UPDATE SET v = $1 WHEN NOT MATCHED BY SOURCE THEN DELETE", sql)
+}
+
+func TestMergeBuilderDoNothing(t *testing.T) {
+	sql, _, err := Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().DoNothing().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DO NOTHING", sql)
+
+	sql, _, err = Merge("t").Using("s", "").On("t.id = s.id").
+		WhenNotMatched("s.v IS NULL").DoNothing().
+		WhenNotMatchedBySource().DoNothing().
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id "+
+		"WHEN NOT MATCHED AND s.v IS NULL THEN DO NOTHING WHEN NOT MATCHED BY SOURCE THEN DO NOTHING", sql)
+
+	b := Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().DoNothing()
+
+	sql, _, err = b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DO NOTHING", sql)
+
+	_, _, err = b.Dialect(SQLServer).ToSql()
+	assert.EqualError(t, err, "dialect sqlserver does not support DO NOTHING in MERGE statements")
+}
+
+func TestMergeBuilderRunners(t *testing.T) {
+	db := &DBStub{}
+	b := Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().Delete().RunWith(db)
+
+	expectedSQL := "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE"
+
+	b.Exec()
+	assert.Equal(t, expectedSQL, db.LastExecSql)
+}
+
+func TestMergeBuilderNoRunner(t *testing.T) {
+	b := Merge("t").Using("s", "").On("t.id = s.id").WhenMatched().Delete()
+
+	_, err := b.Exec()
+	assert.Equal(t, RunnerNotSet, err)
+}
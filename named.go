@@ -0,0 +1,127 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+type namedExpr struct {
+	sql    string
+	params interface{}
+}
+
+// NamedExpr builds an expression from a SQL fragment with named parameters,
+// written as :name or @name, bound from params.
+//
+// params must be a map[string]interface{} (e.g. Eq) or a struct, or pointer
+// to struct, whose fields are matched by their `db` tag or, if untagged, by
+// their Go name. Each parameter is expanded to a ? placeholder, in order, so a
+// name may be used any number of times and the expression composes with any
+// PlaceholderFormat. Parameter values that are Sqlizers are expanded in place.
+//
+// Ex:
+//
+//	NamedExpr("tenant_id = :tenant AND (owner = :user OR editor = :user)",
+//	    map[string]interface{}{"tenant": 1, "user": 2})
+func NamedExpr(sql string, params interface{}) Sqlizer {
+	return namedExpr{sql: sql, params: params}
+}
+
+func (e namedExpr) ToSql() (sql string, args []interface{}, err error) {
+	lookup, err := namedParamLookup(e.params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(e.sql); {
+		if end := skipQuoted(e.sql, i); end > i {
+			buf.WriteString(e.sql[i:end])
+			i = end
+			continue
+		}
+
+		name, end := namedParamAt(e.sql, i)
+		if end == i {
+			buf.WriteByte(e.sql[i])
+			i++
+			continue
+		}
+
+		val, ok := lookup(name)
+		if !ok {
+			return "", nil, fmt.Errorf("named parameter %q has no value", name)
+		}
+		if s, ok := val.(Sqlizer); ok {
+			vsql, vargs, err := nestedToSql(s)
+			if err != nil {
+				return "", nil, err
+			}
+			buf.WriteString(vsql)
+			args = append(args, vargs...)
+		} else {
+			buf.WriteString("?")
+			args = append(args, val)
+		}
+		i = end
+	}
+	return buf.String(), args, nil
+}
+
+// namedParamAt returns the name of the :name or @name parameter starting at
+// sql[i] and the index just past it, or i if there is none. Postgres casts
+// (::type), MySQL system variables (@@var) and assignments (:=) are not
+// parameters.
+func namedParamAt(sql string, i int) (string, int) {
+	c := sql[i]
+	if c != ':' && c != '@' {
+		return "", i
+	}
+	if i > 0 && (sql[i-1] == c || isIdentChar(sql[i-1])) {
+		return "", i
+	}
+	j := i + 1
+	if j >= len(sql) || !isIdentChar(sql[j]) || sql[j] == '$' || (sql[j] >= '0' && sql[j] <= '9') {
+		return "", i
+	}
+	for j < len(sql) && isIdentChar(sql[j]) && sql[j] != '$' {
+		j++
+	}
+	return sql[i+1 : j], j
+}
+
+func namedParamLookup(params interface{}) (func(string) (interface{}, bool), error) {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		return mapLookup(p), nil
+	case Eq:
+		return mapLookup(p), nil
+	}
+
+	v, ok := indirectStruct(reflect.ValueOf(params))
+	if !ok {
+		return nil, fmt.Errorf("named parameters must be a map[string]interface{} or struct, not %T", params)
+	}
+	fields := structFields(v.Type())
+	return func(name string) (interface{}, bool) {
+		for _, f := range fields {
+			if f.name != name {
+				continue
+			}
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok {
+				return nil, true
+			}
+			return fv.Interface(), true
+		}
+		return nil, false
+	}, nil
+}
+
+func mapLookup(m map[string]interface{}) func(string) (interface{}, bool) {
+	return func(name string) (interface{}, bool) {
+		val, ok := m[name]
+		return val, ok
+	}
+}
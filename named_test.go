@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedExprMap(t *testing.T) {
+	b := NamedExpr(
+		"tenant_id = :tenant AND (owner = :user OR editor = @user)",
+		map[string]interface{}{"tenant": 1, "user": 2},
+	)
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "tenant_id = ? AND (owner = ? OR editor = ?)"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{1, 2, 2}
+	assert.Equal(t, expectedArgs, args)
+}
+
+type namedParams struct {
+	Tenant  int `db:"tenant_id"`
+	Since   time.Time
+	Ignored string `db:"-"`
+	namedEmbedded
+}
+
+type namedEmbedded struct {
+	Status string `db:"status"`
+}
+
+func TestNamedExprStruct(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := &namedParams{Tenant: 7, Since: since, namedEmbedded: namedEmbedded{Status: "open"}}
+
+	sql, args, err := NamedExpr("tenant_id = :tenant_id AND created_at >= :Since AND status = :status", params).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant_id = ? AND created_at >= ? AND status = ?", sql)
+	assert.Equal(t, []interface{}{7, since, "open"}, args)
+
+	_, _, err = NamedExpr("x = :Ignored", params).ToSql()
+	assert.Error(t, err)
+}
+
+func TestNamedExprSkipsCastsAndLiterals(t *testing.T) {
+	b := NamedExpr(
+		"a::text = :a AND b = ':b' AND c = @@session.c AND d := 1 -- :d",
+		map[string]interface{}{"a": "x"},
+	)
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a::text = ? AND b = ':b' AND c = @@session.c AND d := 1 -- :d", sql)
+	assert.Equal(t, []interface{}{"x"}, args)
+}
+
+func TestNamedExprMissingParam(t *testing.T) {
+	_, _, err := NamedExpr("a = :a", Eq{"b": 1}).ToSql()
+	assert.EqualError(t, err, `named parameter "a" has no value`)
+
+	_, _, err = NamedExpr("a = :a", 1).ToSql()
+	assert.Error(t, err)
+}
+
+func TestNamedExprNestedSelect(t *testing.T) {
+	sub := Select("id").From("tenants").Where("region = ?", "eu")
+	b := Select("*").
+		From("orders").
+		Where("total > ?", 100).
+		WhereNamed("tenant_id IN (:tenants) AND owner = :user", Eq{"tenants": sub, "user": 5}).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM orders WHERE total > $1 AND tenant_id IN (SELECT id FROM tenants WHERE region = $2) AND owner = $3", sql)
+	assert.Equal(t, []interface{}{100, "eu", 5}, args)
+}
+
+func TestSelectBuilderNamed(t *testing.T) {
+	params := Eq{"lo": 1, "hi": 10}
+	sql, args, err := Select("id").
+		ColumnNamed("CASE WHEN score BETWEEN :lo AND :hi THEN 1 END AS in_range", params).
+		From("t").
+		WhereNamed("score >= :lo", params).
+		SuffixNamed("LIMIT :hi", params).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id, CASE WHEN score BETWEEN ? AND ? THEN 1 END AS in_range FROM t WHERE score >= ? LIMIT ?", sql)
+	assert.Equal(t, []interface{}{1, 10, 1, 10}, args)
+}
@@ -29,6 +29,13 @@ func (p part) ToSql() (sql string, args []interface{}, err error) {
 	return
 }
 
+func (p part) withDialect(d Dialect) Sqlizer {
+	if pred, ok := p.pred.(Sqlizer); ok {
+		return &part{pred: adaptToDialect(pred, d), args: p.args}
+	}
+	return &p
+}
+
 func nestedToSql(s Sqlizer) (string, []interface{}, error) {
 	if raw, ok := s.(rawSqlizer); ok {
 		return raw.toSqlRaw()
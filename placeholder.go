@@ -3,6 +3,7 @@ package squirrel
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -86,29 +87,192 @@ func Placeholders(count int) string {
 }
 
 func replacePositionalPlaceholders(sql, prefix string) (string, error) {
+	segments := splitPlaceholders(sql, true)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(segments[0])
+	for i, segment := range segments[1:] {
+		fmt.Fprintf(buf, "%s%d", prefix, i+1)
+		buf.WriteString(segment)
+	}
+	return buf.String(), nil
+}
+
+// splitPlaceholders splits sql around each ? placeholder, so that the result
+// always has one more element than there are placeholders.
+//
+// Question marks inside string literals, quoted identifiers, dollar-quoted
+// strings and comments are not placeholders, and neither are the Postgres
+// JSONB operators ?| and ?&. If unescape is true, the ?? escape sequence is
+// replaced by a single ? everywhere; otherwise it is left as is.
+func splitPlaceholders(sql string, unescape bool) []string {
+	var segments []string
 	buf := &bytes.Buffer{}
-	i := 0
-	for {
-		p := strings.Index(sql, "?")
-		if p == -1 {
-			break
+	for i := 0; i < len(sql); {
+		if end := skipQuoted(sql, i); end > i {
+			quoted := sql[i:end]
+			if unescape {
+				quoted = strings.ReplaceAll(quoted, "??", "?")
+			}
+			buf.WriteString(quoted)
+			i = end
+			continue
+		}
+
+		if sql[i] != '?' {
+			buf.WriteByte(sql[i])
+			i++
+			continue
 		}
 
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			buf.WriteString(sql[:p])
-			buf.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
+		switch {
+		case strings.HasPrefix(sql[i:], "??"): // escape ?? => ?
+			if unescape {
+				buf.WriteString("?")
+			} else {
+				buf.WriteString("??")
 			}
-			sql = sql[p+2:]
-		} else {
+			i += 2
+		case isJSONBOperator(sql[i:]):
+			buf.WriteString(sql[i : i+2])
+			i += 2
+		default:
+			segments = append(segments, buf.String())
+			buf.Reset()
 			i++
-			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "%s%d", prefix, i)
-			sql = sql[p+1:]
 		}
 	}
+	return append(segments, buf.String())
+}
 
-	buf.WriteString(sql)
-	return buf.String(), nil
+// splitPositionalPlaceholders is like splitPlaceholders, but for SQL whose
+// placeholders have already been replaced by a positional PlaceholderFormat
+// using prefix. It also returns the zero-based argument index referenced by
+// each placeholder.
+func splitPositionalPlaceholders(sql, prefix string) (segments []string, indexes []int) {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(sql); {
+		if end := skipQuoted(sql, i); end > i {
+			buf.WriteString(sql[i:end])
+			i = end
+			continue
+		}
+
+		if strings.HasPrefix(sql[i:], prefix) && (i == 0 || !isIdentChar(sql[i-1])) {
+			j := i + len(prefix)
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			if n, err := strconv.Atoi(sql[i+len(prefix) : j]); err == nil {
+				segments = append(segments, buf.String())
+				buf.Reset()
+				indexes = append(indexes, n-1)
+				i = j
+				continue
+			}
+		}
+
+		buf.WriteByte(sql[i])
+		i++
+	}
+	return append(segments, buf.String()), indexes
+}
+
+// isJSONBOperator reports whether s starts with one of the Postgres ?| or ?&
+// operators. "?||" is a placeholder followed by string concatenation.
+func isJSONBOperator(s string) bool {
+	if strings.HasPrefix(s, "?||") {
+		return false
+	}
+	return strings.HasPrefix(s, "?|") || strings.HasPrefix(s, "?&")
+}
+
+// skipQuoted returns the index just past the string literal, quoted
+// identifier, dollar-quoted string or comment starting at sql[i], or i if
+// none starts there. Unterminated literals and comments run to the end of sql.
+func skipQuoted(sql string, i int) int {
+	switch c := sql[i]; {
+	case c == '\'':
+		// E'...' strings allow backslash escapes.
+		backslash := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') &&
+			(i == 1 || !isIdentChar(sql[i-2]))
+		return skipDelimited(sql, i, c, backslash)
+	case c == '"' || c == '`':
+		return skipDelimited(sql, i, c, false)
+	case strings.HasPrefix(sql[i:], "--"):
+		end := strings.IndexByte(sql[i:], '\n')
+		if end == -1 {
+			return len(sql)
+		}
+		return i + end + 1
+	case strings.HasPrefix(sql[i:], "/*"):
+		// Block comments nest in Postgres and the SQL standard.
+		depth := 0
+		for j := i; j < len(sql)-1; j++ {
+			switch sql[j : j+2] {
+			case "/*":
+				depth++
+				j++
+			case "*/":
+				depth--
+				j++
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return len(sql)
+	case c == '$':
+		return skipDollarQuoted(sql, i)
+	}
+	return i
+}
+
+func skipDelimited(sql string, i int, delim byte, backslash bool) int {
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			if backslash {
+				j++
+			}
+		case delim:
+			if j+1 < len(sql) && sql[j+1] == delim {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted handles Postgres $tag$...$tag$ strings. A tag may not start
+// with a digit, so positional placeholders like $1 are never mistaken for one.
+func skipDollarQuoted(sql string, i int) int {
+	if i > 0 && isIdentChar(sql[i-1]) {
+		return i
+	}
+	j := i + 1
+	for j < len(sql) && sql[j] != '$' {
+		c := sql[j]
+		if !isIdentChar(c) || (j == i+1 && c >= '0' && c <= '9') {
+			return i
+		}
+		j++
+	}
+	if j >= len(sql) {
+		return i
+	}
+	tag := sql[i : j+1]
+	end := strings.Index(sql[j+1:], tag)
+	if end == -1 {
+		return i
+	}
+	return j + 1 + end + len(tag)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c >= 0x80
 }
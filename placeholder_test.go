@@ -38,19 +38,54 @@ func TestPlaceholders(t *testing.T) {
 func TestEscapeDollar(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Dollar.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['$1'] AND enabled = $2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = $1", s)
 }
 
 func TestEscapeColon(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Colon.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array[':1'] AND enabled = :2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = :1", s)
 }
 
 func TestEscapeAtp(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := AtP.ReplacePlaceholders(sql)
-	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['@p1'] AND enabled = @p2", s)
+	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = @p1", s)
+}
+
+func TestReplacePlaceholdersSkipsLiterals(t *testing.T) {
+	sql := "SELECT 'what?', \"col?\", `c?`, E'it\\'s ?', $$a ? b$$, $fn$ ? $fn$ " +
+		"FROM t -- why?\n" +
+		"WHERE /* is ? /* nested ? */ still ? */ a = ? AND b = 'it''s ?' AND c = ?"
+
+	tests := []struct {
+		format   PlaceholderFormat
+		expected string
+	}{
+		{Dollar, "a = $1 AND b = 'it''s ?' AND c = $2"},
+		{Colon, "a = :1 AND b = 'it''s ?' AND c = :2"},
+		{AtP, "a = @p1 AND b = 'it''s ?' AND c = @p2"},
+	}
+	for _, test := range tests {
+		s, err := test.format.ReplacePlaceholders(sql)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(s, "SELECT 'what?', \"col?\", `c?`, E'it\\'s ?', $$a ? b$$, $fn$ ? $fn$ FROM t -- why?\n"))
+		assert.True(t, strings.HasSuffix(s, "WHERE /* is ? /* nested ? */ still ? */ "+test.expected), s)
+	}
+}
+
+func TestReplacePlaceholdersJSONBOperators(t *testing.T) {
+	sql := "SELECT * FROM t WHERE data ?| array[?] AND data ?& array[?] AND name = ?||'x'"
+	s, _ := Dollar.ReplacePlaceholders(sql)
+	assert.Equal(t, "SELECT * FROM t WHERE data ?| array[$1] AND data ?& array[$2] AND name = $3||'x'", s)
+}
+
+func TestReplacePlaceholdersUnterminated(t *testing.T) {
+	s, _ := Dollar.ReplacePlaceholders("a = ? AND b = 'x?")
+	assert.Equal(t, "a = $1 AND b = 'x?", s)
+
+	s, _ = Dollar.ReplacePlaceholders("a = ? AND $$ ?")
+	assert.Equal(t, "a = $1 AND $$ $2", s)
 }
 
 func BenchmarkPlaceholdersArray(b *testing.B) {
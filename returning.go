@@ -0,0 +1,100 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ReturningStyle describes how a database returns rows from INSERT, UPDATE
+// and DELETE statements.
+type ReturningStyle int
+
+const (
+	// ReturningUnsupported means the database cannot return rows from data
+	// modifying statements.
+	ReturningUnsupported ReturningStyle = iota
+
+	// ReturningClause is the trailing RETURNING clause of PostgreSQL, SQLite
+	// and MariaDB.
+	ReturningClause
+
+	// ReturningOutput is the OUTPUT clause of SQL Server, which refers to
+	// columns through the INSERTED and DELETED pseudo tables.
+	ReturningOutput
+)
+
+// StatementKind is the kind of a data modifying statement, passed to
+// Dialect.ReturningStyle.
+type StatementKind string
+
+const (
+	// StatementInsert is an INSERT statement.
+	StatementInsert StatementKind = "INSERT"
+
+	// StatementUpdate is an UPDATE statement.
+	StatementUpdate StatementKind = "UPDATE"
+
+	// StatementDelete is a DELETE statement.
+	StatementDelete StatementKind = "DELETE"
+)
+
+// returningColumn is a column added with Returning. Under ReturningOutput it
+// is qualified with the INSERTED or DELETED pseudo table.
+type returningColumn string
+
+func (c returningColumn) ToSql() (string, []interface{}, error) {
+	return string(c), nil, nil
+}
+
+func newReturningColumns(columns []string) []interface{} {
+	parts := make([]interface{}, len(columns))
+	for i, col := range columns {
+		parts[i] = returningColumn(col)
+	}
+	return parts
+}
+
+func returningStyle(d Dialect, kind StatementKind) ReturningStyle {
+	if d == nil {
+		return ReturningClause
+	}
+	return d.ReturningStyle(kind)
+}
+
+// returningToSql returns the RETURNING or OUTPUT clause for parts of a
+// statement of the given kind if the Dialect renders it in the given style,
+// or "" otherwise, so that it can be called at each position the clause may
+// appear. pseudoTable qualifies plain columns in an OUTPUT clause.
+func returningToSql(d Dialect, kind StatementKind, style ReturningStyle, parts []Sqlizer, pseudoTable string) (string, []interface{}, error) {
+	if len(parts) == 0 {
+		return "", nil, nil
+	}
+	actual := returningStyle(d, kind)
+	if actual == ReturningUnsupported {
+		return "", nil, fmt.Errorf("dialect %s does not support RETURNING in %s statements", d.Name(), kind)
+	}
+	if actual != style {
+		return "", nil, nil
+	}
+
+	sql := &bytes.Buffer{}
+	if style == ReturningClause {
+		sql.WriteString("RETURNING ")
+	} else {
+		sql.WriteString("OUTPUT ")
+		qualified := make([]Sqlizer, len(parts))
+		for i, p := range parts {
+			if col, ok := p.(returningColumn); ok && !strings.Contains(string(col), ".") {
+				p = returningColumn(pseudoTable + "." + string(col))
+			}
+			qualified[i] = p
+		}
+		parts = qualified
+	}
+	args, err := appendToSql(parts, sql, ", ", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql.String(), args, nil
+}
@@ -0,0 +1,82 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBuilderReturning(t *testing.T) {
+	b := Insert("users").
+		Columns("name").
+		Values("moe").
+		OnConflict("name").DoNothing().
+		Returning("id", "created_at").
+		ReturningExpr(Expr("? AS source", "api")).
+		Suffix("-- tagged")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) VALUES (?) ON CONFLICT (name) DO NOTHING "+
+		"RETURNING id, created_at, ? AS source -- tagged", sql)
+	assert.Equal(t, []interface{}{"moe", "api"}, args)
+}
+
+func TestUpdateBuilderReturning(t *testing.T) {
+	b := Update("users").Set("name", "larry").Where("id = ?", 1).Returning("id", "name")
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = $1 WHERE id = $2 RETURNING id, name", sql)
+	assert.Equal(t, []interface{}{"larry", 1}, args)
+}
+
+func TestDeleteBuilderReturning(t *testing.T) {
+	sql, args, err := Delete("users").Where("id = ?", 1).Returning("*").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE id = ? RETURNING *", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestReturningSQLServerOutput(t *testing.T) {
+	sb := StatementBuilder.Dialect(SQLServer)
+
+	sql, args, err := sb.Insert("users").Columns("name").Values("moe").
+		Returning("id", "u.name").
+		ReturningExpr(Expr("@@ROWCOUNT")).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) OUTPUT INSERTED.id, u.name, @@ROWCOUNT VALUES (@p1)", sql)
+	assert.Equal(t, []interface{}{"moe"}, args)
+
+	sql, _, err = sb.Update("users").Set("name", "larry").Where("id = ?", 1).Returning("name").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = @p1 OUTPUT INSERTED.name WHERE id = @p2", sql)
+
+	sql, _, err = sb.Delete("users").Where("id = ?", 1).Returning("*").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users OUTPUT DELETED.* WHERE id = @p1", sql)
+}
+
+func TestReturningUnsupported(t *testing.T) {
+	_, _, err := Delete("users").Dialect(Oracle).Returning("id").ToSql()
+	assert.EqualError(t, err, "dialect oracle does not support RETURNING in DELETE statements")
+
+	_, _, err = Delete("users").Dialect(MySQL).Returning("id").ToSql()
+	assert.EqualError(t, err, "dialect mysql does not support RETURNING in DELETE statements")
+}
+
+func TestReturningMariaDB(t *testing.T) {
+	sql, _, err := Delete("users").Where("id = ?", 1).Dialect(MariaDB).Returning("id").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE id = ? RETURNING id", sql)
+}
+
+func TestReturningMariaDBUpdate(t *testing.T) {
+	_, _, err := Update("users").Set("name", "larry").Dialect(MariaDB).Returning("id").ToSql()
+	assert.EqualError(t, err, "dialect mariadb does not support RETURNING in UPDATE statements")
+
+	sql, _, err := Insert("users").Columns("name").Values("moe").Dialect(MariaDB).Returning("id").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name) VALUES (?) RETURNING id", sql)
+}
@@ -0,0 +1,215 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryContexter is the interface that wraps the QueryContext method of the
+// statement builders, e.g. SelectBuilder or an InsertBuilder with Returning.
+type QueryContexter interface {
+	QueryContext(ctx context.Context) (*sql.Rows, error)
+}
+
+// ScanError is returned by ScanAll and ScanOne when the columns of a result do
+// not match the fields of the struct type scanned into.
+type ScanError struct {
+	// Type is the struct type scanned into.
+	Type reflect.Type
+
+	// MissingColumns are the mapped fields of Type that have no result column.
+	MissingColumns []string
+
+	// ExtraColumns are the result columns that have no field in Type.
+	ExtraColumns []string
+}
+
+func (e *ScanError) Error() string {
+	var problems []string
+	if len(e.ExtraColumns) > 0 {
+		problems = append(problems, fmt.Sprintf("no field for columns %s", strings.Join(e.ExtraColumns, ", ")))
+	}
+	if len(e.MissingColumns) > 0 {
+		problems = append(problems, fmt.Sprintf("no column for fields %s", strings.Join(e.MissingColumns, ", ")))
+	}
+	return fmt.Sprintf("cannot scan into %s: %s", e.Type, strings.Join(problems, "; "))
+}
+
+// QueryAll runs q with QueryContext and scans every row into a T.
+//
+// See ScanAll.
+func QueryAll[T any](ctx context.Context, q QueryContexter) ([]T, error) {
+	rows, err := q.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ScanAll[T](rows)
+}
+
+// QueryOne runs q with QueryContext and scans the first row into a T. It
+// returns sql.ErrNoRows if there is no row.
+//
+// See ScanAll.
+func QueryOne[T any](ctx context.Context, q QueryContexter) (T, error) {
+	rows, err := q.QueryContext(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return ScanOne[T](rows)
+}
+
+// ScanAll scans every row of rows into a T and closes rows.
+//
+// If T is a struct, columns are mapped to its fields by their `db` tags or, if
+// untagged, by their Go name; fields of embedded structs are promoted. Every
+// column must have a field and every field a column, or a *ScanError is
+// returned. Fields may be of any type database/sql can scan into, such as
+// sql.NullString. If T is not a struct, or is a struct implementing
+// sql.Scanner such as sql.NullInt64 or time.Time, rows must have a single
+// column, which is scanned into T directly.
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	scan, err := newRowScanner[T](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []T
+	for rows.Next() {
+		var dest T
+		if err := scan(rows, &dest); err != nil {
+			return nil, err
+		}
+		all = append(all, dest)
+	}
+	return all, rows.Err()
+}
+
+// ScanOne scans the first row of rows into a T and closes rows. It returns
+// sql.ErrNoRows if there is no row.
+//
+// See ScanAll.
+func ScanOne[T any](rows *sql.Rows) (T, error) {
+	defer rows.Close()
+
+	var dest T
+	scan, err := newRowScanner[T](rows)
+	if err != nil {
+		return dest, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return dest, err
+		}
+		return dest, sql.ErrNoRows
+	}
+	if err := scan(rows, &dest); err != nil {
+		return dest, err
+	}
+	return dest, rows.Close()
+}
+
+// newRowScanner returns a function scanning the current row of rows into a
+// *T, using the cached scan plan for T and the columns of rows.
+func newRowScanner[T any](rows *sql.Rows) (func(*sql.Rows, *T) error, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := scanPlanFor(reflect.TypeOf((*T)(nil)).Elem(), columns)
+	if err != nil {
+		return nil, err
+	}
+	dests := make([]interface{}, len(columns))
+	return func(rows *sql.Rows, dest *T) error {
+		if err := plan.bind(reflect.ValueOf(dest).Elem(), dests); err != nil {
+			return err
+		}
+		return rows.Scan(dests...)
+	}, nil
+}
+
+// scanPlan maps each result column to the index of its struct field; a nil
+// fields means the row has a single column scanned into the value itself.
+type scanPlan struct {
+	fields [][]int
+}
+
+func (p *scanPlan) bind(v reflect.Value, dests []interface{}) error {
+	if p.fields == nil {
+		dests[0] = v.Addr().Interface()
+		return nil
+	}
+	for i, index := range p.fields {
+		fv, ok := fieldByIndexAlloc(v, index)
+		if !ok {
+			return fmt.Errorf("cannot scan into %s: nil pointer to unexported embedded struct", v.Type())
+		}
+		dests[i] = fv.Addr().Interface()
+	}
+	return nil
+}
+
+type scanPlanKey struct {
+	t       reflect.Type
+	columns string
+}
+
+var scanPlanCache sync.Map // map[scanPlanKey]*scanPlan
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+func scanPlanFor(t reflect.Type, columns []string) (*scanPlan, error) {
+	key := scanPlanKey{t: t, columns: strings.Join(columns, "\x00")}
+	if plan, ok := scanPlanCache.Load(key); ok {
+		return plan.(*scanPlan), nil
+	}
+
+	plan := &scanPlan{}
+	if t.Kind() != reflect.Struct || t == timeType || reflect.PointerTo(t).Implements(scannerType) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("cannot scan %d columns into %s", len(columns), t)
+		}
+	} else {
+		// structFields maps each name to a single field, the shallowest.
+		fields := structFields(t)
+		byName := make(map[string][]int, len(fields))
+		for _, f := range fields {
+			byName[f.name] = f.index
+		}
+
+		scanErr := &ScanError{Type: t}
+		seen := make(map[string]bool, len(columns))
+		plan.fields = make([][]int, len(columns))
+		for i, col := range columns {
+			index, ok := byName[col]
+			if !ok {
+				scanErr.ExtraColumns = append(scanErr.ExtraColumns, col)
+				continue
+			}
+			plan.fields[i] = index
+			seen[col] = true
+		}
+		for _, f := range fields {
+			if !seen[f.name] {
+				scanErr.MissingColumns = append(scanErr.MissingColumns, f.name)
+			}
+		}
+		if len(scanErr.ExtraColumns) > 0 || len(scanErr.MissingColumns) > 0 {
+			return nil, scanErr
+		}
+	}
+
+	scanPlanCache.Store(key, plan)
+	return plan, nil
+}
@@ -0,0 +1,90 @@
+package squirrel
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scanBase struct {
+	ID int `db:"id"`
+}
+
+type ScanBase struct {
+	ID int `db:"id"`
+}
+
+type scanModel struct {
+	*ScanBase
+	Name  sql.NullString `db:"name"`
+	Count int
+}
+
+func TestScanPlanStruct(t *testing.T) {
+	plan, err := scanPlanFor(reflect.TypeOf(scanModel{}), []string{"name", "id", "Count"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{1}, {0, 0}, {2}}, plan.fields)
+
+	cached, err := scanPlanFor(reflect.TypeOf(scanModel{}), []string{"name", "id", "Count"})
+	assert.NoError(t, err)
+	assert.True(t, plan == cached)
+
+	var m scanModel
+	dests := make([]interface{}, 3)
+	assert.NoError(t, plan.bind(reflect.ValueOf(&m).Elem(), dests))
+	assert.NotNil(t, m.ScanBase)
+	assert.Equal(t, &m.ID, dests[1])
+}
+
+func TestScanPlanUnexportedEmbedded(t *testing.T) {
+	type model struct {
+		*scanBase
+	}
+	plan, err := scanPlanFor(reflect.TypeOf(model{}), []string{"id"})
+	assert.NoError(t, err)
+	assert.Error(t, plan.bind(reflect.ValueOf(&model{}).Elem(), make([]interface{}, 1)))
+
+	m := model{scanBase: &scanBase{}}
+	dests := make([]interface{}, 1)
+	assert.NoError(t, plan.bind(reflect.ValueOf(&m).Elem(), dests))
+	assert.Equal(t, &m.ID, dests[0])
+}
+
+func TestScanPlanShadowedEmbedded(t *testing.T) {
+	type model struct {
+		ID string `db:"id"`
+		*ScanBase
+	}
+	plan, err := scanPlanFor(reflect.TypeOf(model{}), []string{"id"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{0}}, plan.fields)
+
+	var m model
+	dests := make([]interface{}, 1)
+	assert.NoError(t, plan.bind(reflect.ValueOf(&m).Elem(), dests))
+	assert.Equal(t, &m.ID, dests[0])
+	assert.Nil(t, m.ScanBase)
+}
+
+func TestScanPlanErrors(t *testing.T) {
+	_, err := scanPlanFor(reflect.TypeOf(scanModel{}), []string{"id", "extra"})
+	assert.IsType(t, &ScanError{}, err)
+
+	scanErr := err.(*ScanError)
+	assert.Equal(t, []string{"extra"}, scanErr.ExtraColumns)
+	assert.Equal(t, []string{"name", "Count"}, scanErr.MissingColumns)
+	assert.Equal(t, "cannot scan into squirrel.scanModel: no field for columns extra; no column for fields name, Count", err.Error())
+
+	_, err = scanPlanFor(reflect.TypeOf(0), []string{"a", "b"})
+	assert.Error(t, err)
+}
+
+func TestScanPlanScalar(t *testing.T) {
+	for _, typ := range []reflect.Type{reflect.TypeOf(""), reflect.TypeOf(sql.NullInt64{})} {
+		plan, err := scanPlanFor(typ, []string{"v"})
+		assert.NoError(t, err)
+		assert.Nil(t, plan.fields)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 type selectData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
 	CTEs              []Sqlizer
@@ -19,13 +20,20 @@ type selectData struct {
 	From              Sqlizer
 	Joins             []Sqlizer
 	Compounds         []Sqlizer
+	ParenCompounds    bool
 	WhereParts        []Sqlizer
 	GroupBys          []string
 	HavingParts       []Sqlizer
+	WindowParts       []Sqlizer
 	OrderByParts      []Sqlizer
 	Limit             string
 	Offset            string
+	Lock              LockStrength
+	LockOf            []string
+	LockWait          string
 	Suffixes          []Sqlizer
+	Comment           map[string]string
+	CommentPrefix     bool
 }
 
 func (d *selectData) Exec() (sql.Result, error) {
@@ -59,6 +67,8 @@ func (d *selectData) ToSql() (sqlStr string, args []interface{}, err error) {
 		return
 	}
 
+	sqlStr = withComment(sqlStr, d.Comment, d.CommentPrefix)
+
 	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
 	return
 }
@@ -82,13 +92,18 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.CTEs) > 0 {
 		sql.WriteString("WITH ")
-		args, err = appendToSql(d.CTEs, sql, ", ", args)
+		args, err = appendToSql(applyDialect(d.CTEs, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return
 		}
 		sql.WriteString(" ")
 	}
 
+	parenthesize := d.ParenCompounds && len(d.Compounds) > 0
+	if parenthesize {
+		sql.WriteString("(")
+	}
+
 	sql.WriteString("SELECT ")
 
 	if len(d.Options) > 0 {
@@ -96,8 +111,14 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(" ")
 	}
 
+	top, limitClause := renderLimitOffset(d.Dialect, d.Limit, d.Offset)
+	if len(top) > 0 {
+		sql.WriteString(top)
+		sql.WriteString(" ")
+	}
+
 	if len(d.Columns) > 0 {
-		args, err = appendToSql(d.Columns, sql, ", ", args)
+		args, err = appendToSql(applyDialect(d.Columns, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return
 		}
@@ -105,7 +126,7 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if d.From != nil {
 		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSql([]Sqlizer{adaptToDialect(d.From, d.Dialect)}, sql, "", args)
 		if err != nil {
 			return
 		}
@@ -113,7 +134,7 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.Joins) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
+		args, err = appendToSql(applyDialect(d.Joins, d.Dialect), sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -121,7 +142,7 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(applyDialect(d.WhereParts, d.Dialect), sql, " AND ", args)
 		if err != nil {
 			return
 		}
@@ -134,15 +155,23 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.HavingParts) > 0 {
 		sql.WriteString(" HAVING ")
-		args, err = appendToSql(d.HavingParts, sql, " AND ", args)
+		args, err = appendToSql(applyDialect(d.HavingParts, d.Dialect), sql, " AND ", args)
+		if err != nil {
+			return
+		}
+	}
+
+	if len(d.WindowParts) > 0 {
+		sql.WriteString(" WINDOW ")
+		args, err = appendToSql(applyDialect(d.WindowParts, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	if len(d.Compounds) > 0 {
+	if len(d.Compounds) > 0 && !parenthesize {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Compounds, sql, " ", args)
+		args, err = appendToSql(applyDialect(d.Compounds, d.Dialect), sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -150,20 +179,32 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.OrderByParts) > 0 {
 		sql.WriteString(" ORDER BY ")
-		args, err = appendToSql(d.OrderByParts, sql, ", ", args)
+		args, err = appendToSql(applyDialect(d.OrderByParts, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(limitClause) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(limitClause)
+	}
+
+	lockClause, err := d.lockToSql()
+	if err != nil {
+		return
+	}
+	if len(lockClause) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(lockClause)
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	if parenthesize {
+		sql.WriteString(") ")
+		args, err = appendToSql(parenthesizeCompounds(applyDialect(d.Compounds, d.Dialect)), sql, " ", args)
+		if err != nil {
+			return
+		}
 	}
 
 	if len(d.Suffixes) > 0 {
@@ -207,6 +248,14 @@ func newSelectJoinPart(joinType string, sel SelectBuilder, alias string, onClaus
 	}
 }
 
+func (p selectJoinPart) withDialect(d Dialect) Sqlizer {
+	p.target = adaptToDialect(p.target, d)
+	if on, ok := p.onClause.(Sqlizer); ok {
+		p.onClause = adaptToDialect(on, d)
+	}
+	return p
+}
+
 func (p selectJoinPart) ToSql() (string, []interface{}, error) {
 	if strings.TrimSpace(p.joinType) == "" {
 		return "", nil, fmt.Errorf("join type must not be empty")
@@ -269,6 +318,7 @@ func (p selectJoinPart) ToSql() (string, []interface{}, error) {
 type compoundSelectPart struct {
 	operator string
 	query    SelectBuilder
+	paren    bool
 }
 
 func newCompoundSelectPart(operator string, sel SelectBuilder) compoundSelectPart {
@@ -279,6 +329,11 @@ func newCompoundSelectPart(operator string, sel SelectBuilder) compoundSelectPar
 	}
 }
 
+func (p compoundSelectPart) withDialect(d Dialect) Sqlizer {
+	p.query = selectWithDialect(p.query, d)
+	return p
+}
+
 func (p compoundSelectPart) ToSql() (string, []interface{}, error) {
 	if strings.TrimSpace(p.operator) == "" {
 		return "", nil, fmt.Errorf("compound operator must not be empty")
@@ -290,9 +345,26 @@ func (p compoundSelectPart) ToSql() (string, []interface{}, error) {
 	if strings.TrimSpace(sql) == "" {
 		return "", nil, fmt.Errorf("compound SELECT must not be empty")
 	}
+	if p.paren {
+		sql = "(" + sql + ")"
+	}
 	return p.operator + " " + sql, args, nil
 }
 
+// parenthesizeCompounds returns compounds with each SelectBuilder operand
+// wrapped in parentheses. Operands added as strings are left as they are.
+func parenthesizeCompounds(compounds []Sqlizer) []Sqlizer {
+	parts := make([]Sqlizer, len(compounds))
+	for i, c := range compounds {
+		if p, ok := c.(compoundSelectPart); ok {
+			p.paren = true
+			c = p
+		}
+		parts[i] = c
+	}
+	return parts
+}
+
 // Format methods
 
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
@@ -301,6 +373,12 @@ func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
 }
 
+// Dialect sets the Dialect used to render the query, along with its default
+// PlaceholderFormat.
+func (b SelectBuilder) Dialect(d Dialect) SelectBuilder {
+	return setDialect(b, d).(SelectBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -418,6 +496,14 @@ func (b SelectBuilder) Column(column interface{}, args ...interface{}) SelectBui
 	return builder.Append(b, "Columns", newPart(column, args...)).(SelectBuilder)
 }
 
+// ColumnNamed adds a result column with named parameters bound from params to
+// the query.
+//
+// See NamedExpr.
+func (b SelectBuilder) ColumnNamed(column string, params interface{}) SelectBuilder {
+	return b.Column(NamedExpr(column, params))
+}
+
 // From sets the FROM clause of the query.
 func (b SelectBuilder) From(from string) SelectBuilder {
 	return builder.Set(b, "From", newPart(from)).(SelectBuilder)
@@ -442,6 +528,42 @@ func (b SelectBuilder) UnionAllSelect(unions ...SelectBuilder) SelectBuilder {
 	return b.unionSelectWithType("UNION ALL", unions...)
 }
 
+// IntersectSelect adds one or more INTERSECT SelectBuilders, which keep only
+// rows returned by every query and remove duplicate rows.
+func (b SelectBuilder) IntersectSelect(selects ...SelectBuilder) SelectBuilder {
+	return b.unionSelectWithType("INTERSECT", selects...)
+}
+
+// IntersectAllSelect adds one or more INTERSECT ALL SelectBuilders, which keep
+// duplicate rows.
+func (b SelectBuilder) IntersectAllSelect(selects ...SelectBuilder) SelectBuilder {
+	return b.unionSelectWithType("INTERSECT ALL", selects...)
+}
+
+// ExceptSelect adds one or more EXCEPT SelectBuilders, which remove the rows
+// they return from the result and remove duplicate rows.
+func (b SelectBuilder) ExceptSelect(selects ...SelectBuilder) SelectBuilder {
+	return b.unionSelectWithType("EXCEPT", selects...)
+}
+
+// ExceptAllSelect adds one or more EXCEPT ALL SelectBuilders, which keep
+// duplicate rows.
+func (b SelectBuilder) ExceptAllSelect(selects ...SelectBuilder) SelectBuilder {
+	return b.unionSelectWithType("EXCEPT ALL", selects...)
+}
+
+// ParenthesizeCompounds wraps the query and each compound SelectBuilder (added
+// with e.g. UnionSelect or IntersectSelect) in parentheses, so that each of
+// them keeps its own ORDER BY and LIMIT:
+//
+//	(SELECT ... LIMIT 10) UNION (SELECT ... LIMIT 10)
+//
+// The ORDER BY and LIMIT of the query then apply to its first operand only; to
+// order or limit the combined result, select from it with FromSelect.
+func (b SelectBuilder) ParenthesizeCompounds() SelectBuilder {
+	return builder.Set(b, "ParenCompounds", true).(SelectBuilder)
+}
+
 // JoinClause adds a join clause to the query.
 func (b SelectBuilder) JoinClause(pred interface{}, args ...interface{}) SelectBuilder {
 	return builder.Append(b, "Joins", newPart(pred, args...)).(SelectBuilder)
@@ -540,6 +662,13 @@ func (b SelectBuilder) Where(pred interface{}, args ...interface{}) SelectBuilde
 	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(SelectBuilder)
 }
 
+// WhereNamed adds a WHERE expression with named parameters bound from params.
+//
+// See NamedExpr.
+func (b SelectBuilder) WhereNamed(sql string, params interface{}) SelectBuilder {
+	return b.Where(NamedExpr(sql, params))
+}
+
 // GroupBy adds GROUP BY expressions to the query.
 func (b SelectBuilder) GroupBy(groupBys ...string) SelectBuilder {
 	return builder.Extend(b, "GroupBys", groupBys).(SelectBuilder)
@@ -552,6 +681,12 @@ func (b SelectBuilder) Having(pred interface{}, rest ...interface{}) SelectBuild
 	return builder.Append(b, "HavingParts", newWherePart(pred, rest...)).(SelectBuilder)
 }
 
+// Window adds a named window definition to the WINDOW clause of the query,
+// which window functions can refer to with Over(fn, name).
+func (b SelectBuilder) Window(name string, spec WindowBuilder) SelectBuilder {
+	return builder.Append(b, "WindowParts", windowDefinition{name: name, spec: spec}).(SelectBuilder)
+}
+
 // OrderByClause adds ORDER BY clause to the query.
 func (b SelectBuilder) OrderByClause(pred interface{}, args ...interface{}) SelectBuilder {
 	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(SelectBuilder)
@@ -595,3 +730,11 @@ func (b SelectBuilder) Suffix(sql string, args ...interface{}) SelectBuilder {
 func (b SelectBuilder) SuffixExpr(expr Sqlizer) SelectBuilder {
 	return builder.Append(b, "Suffixes", expr).(SelectBuilder)
 }
+
+// SuffixNamed adds an expression with named parameters bound from params to
+// the end of the query.
+//
+// See NamedExpr.
+func (b SelectBuilder) SuffixNamed(sql string, params interface{}) SelectBuilder {
+	return b.SuffixExpr(NamedExpr(sql, params))
+}
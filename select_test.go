@@ -291,6 +291,67 @@ func TestUnionFunctionPlaceholderFormatting(t *testing.T) {
 	assert.Equal(t, []interface{}{1, 2, 3}, args)
 }
 
+func TestSelectBuilderIntersectExceptSelect(t *testing.T) {
+	b := Select("id").
+		From("users").
+		IntersectSelect(Select("id").From("admins").Where("active = ?", true)).
+		ExceptAllSelect(Select("id").From("banned")).
+		OrderBy("id")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id FROM users INTERSECT SELECT id FROM admins WHERE active = ? " +
+		"EXCEPT ALL SELECT id FROM banned ORDER BY id"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{true}, args)
+}
+
+func TestIntersectExceptFunctions(t *testing.T) {
+	a := Select("id").From("a")
+	b := Select("id").From("b")
+
+	sql, _, err := Intersect(a, b).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a INTERSECT SELECT id FROM b", sql)
+
+	sql, _, err = IntersectAll(a, b).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a INTERSECT ALL SELECT id FROM b", sql)
+
+	sql, _, err = Except(a, b).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a EXCEPT SELECT id FROM b", sql)
+
+	sql, _, err = ExceptAll(a, b).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a EXCEPT ALL SELECT id FROM b", sql)
+}
+
+func TestSelectBuilderParenthesizeCompounds(t *testing.T) {
+	b := Except(
+		Select("id").From("a").Where("x = ?", 1).OrderBy("id").Limit(10),
+		Select("id").From("b").Where("y = ?", 2).OrderBy("id DESC").Limit(5),
+	).
+		Prefix("/* q */").
+		With("c", Select("1")).
+		ParenthesizeCompounds().
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "/* q */ WITH c AS (SELECT 1) " +
+		"(SELECT id FROM a WHERE x = $1 ORDER BY id LIMIT 10) " +
+		"EXCEPT (SELECT id FROM b WHERE y = $2 ORDER BY id DESC LIMIT 5)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, _, err = Select("id").From("a").ParenthesizeCompounds().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a", sql)
+}
+
 func TestSelectWithOptions(t *testing.T) {
 	sql, _, err := Select("*").From("foo").Distinct().Options("SQL_NO_CACHE").ToSql()
 
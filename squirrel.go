@@ -7,7 +7,6 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
-	"strings"
 
 	"github.com/lann/builder"
 )
@@ -83,13 +82,23 @@ func (r *stdsqlRunner) QueryRow(query string, args ...interface{}) RowScanner {
 }
 
 func setRunWith(b interface{}, runner BaseRunner) interface{} {
+	runner = wrapStdSqlRunner(runner)
+	if hooks, ok := builder.Get(b, "hooks"); ok && runner != nil {
+		runner = WrapHooks(runner, hooks.([]Hook)...)
+	}
+	return builder.Set(b, "RunWith", runner)
+}
+
+// wrapStdSqlRunner wraps a *sql.DB, *sql.Tx or similar runner, whose QueryRow
+// returns a *sql.Row, into a Runner.
+func wrapStdSqlRunner(runner BaseRunner) BaseRunner {
 	switch r := runner.(type) {
 	case StdSqlCtx:
-		runner = WrapStdSqlCtx(r)
+		return WrapStdSqlCtx(r)
 	case StdSql:
-		runner = WrapStdSql(r)
+		return WrapStdSql(r)
 	}
-	return builder.Set(b, "RunWith", runner)
+	return runner
 }
 
 // RunnerNotSet is returned by methods that need a Runner if it isn't set.
@@ -122,6 +131,19 @@ func QueryRowWith(db QueryRower, s Sqlizer) RowScanner {
 	return &Row{RowScanner: db.QueryRow(query, args...), err: err}
 }
 
+// placeholderFormatOf returns the PlaceholderFormat set on s if it is one of
+// the statement builders, or nil otherwise.
+func placeholderFormatOf(s Sqlizer) PlaceholderFormat {
+	switch s.(type) {
+	case SelectBuilder, InsertBuilder, UpdateBuilder, DeleteBuilder, MergeBuilder:
+		if f, ok := builder.Get(s, "PlaceholderFormat"); ok {
+			format, _ := f.(PlaceholderFormat)
+			return format
+		}
+	}
+	return nil
+}
+
 // DebugSqlizer calls ToSql on s and shows the approximate SQL to be executed
 //
 // If ToSql returns an error, the result of this method will look like:
@@ -137,47 +159,42 @@ func DebugSqlizer(s Sqlizer) string {
 		return fmt.Sprintf("[ToSql error: %s]", err)
 	}
 
-	var placeholder string
-	downCast, ok := s.(placeholderDebugger)
-	if !ok {
-		placeholder = "?"
-	} else {
+	placeholder := "?"
+	if downCast, ok := s.(placeholderDebugger); ok {
+		placeholder = downCast.debugPlaceholder()
+	} else if downCast, ok := placeholderFormatOf(s).(placeholderDebugger); ok {
 		placeholder = downCast.debugPlaceholder()
 	}
-	// TODO: dedupe this with placeholder.go
-	buf := &bytes.Buffer{}
-	i := 0
-	for {
-		p := strings.Index(sql, placeholder)
-		if p == -1 {
-			break
+
+	var segments []string
+	var indexes []int
+	if placeholder == "?" {
+		segments = splitPlaceholders(sql, true)
+		for i := range segments[1:] {
+			indexes = append(indexes, i)
 		}
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			buf.WriteString(sql[:p])
-			buf.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
-			}
-			sql = sql[p+2:]
-		} else {
-			if i+1 > len(args) {
-				return fmt.Sprintf(
-					"[DebugSqlizer error: too many placeholders in %#v for %d args]",
-					sql, len(args))
-			}
-			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "'%v'", args[i])
-			// advance our sql string "cursor" beyond the arg we placed
-			sql = sql[p+1:]
-			i++
+	} else {
+		segments, indexes = splitPositionalPlaceholders(sql, placeholder)
+	}
+
+	used := make(map[int]bool, len(args))
+	buf := &bytes.Buffer{}
+	buf.WriteString(segments[0])
+	for i, segment := range segments[1:] {
+		n := indexes[i]
+		if n < 0 || n >= len(args) {
+			return fmt.Sprintf(
+				"[DebugSqlizer error: too many placeholders in %#v for %d args]",
+				sql, len(args))
 		}
+		used[n] = true
+		fmt.Fprintf(buf, "'%v'", args[n])
+		buf.WriteString(segment)
 	}
-	if i < len(args) {
+	if len(used) < len(args) {
 		return fmt.Sprintf(
 			"[DebugSqlizer error: not enough placeholders in %#v for %d args]",
 			sql, len(args))
 	}
-	// "append" any remaning sql that won't need interpolating
-	buf.WriteString(sql)
 	return buf.String()
 }
@@ -190,6 +190,15 @@ func TestDebugSqlizer(t *testing.T) {
 	assert.Equal(t, expectedDebug, DebugSqlizer(sqlizer))
 }
 
+func TestDebugSqlizerSkipsLiterals(t *testing.T) {
+	sqlizer := Select("'what?'").From("t").Where("a = ? /* b = ? */ AND c = ?", 1, 2)
+	expectedDebug := "SELECT 'what?' FROM t WHERE a = '1' /* b = ? */ AND c = '2'"
+	assert.Equal(t, expectedDebug, DebugSqlizer(sqlizer))
+	assert.Equal(t, expectedDebug, DebugSqlizer(sqlizer.PlaceholderFormat(Dollar)))
+	assert.Equal(t, expectedDebug, DebugSqlizer(sqlizer.PlaceholderFormat(Colon)))
+	assert.Equal(t, expectedDebug, DebugSqlizer(sqlizer.PlaceholderFormat(AtP)))
+}
+
 func TestDebugSqlizerErrors(t *testing.T) {
 	errorMsg := DebugSqlizer(Expr("x = ?", 1, 2)) // Not enough placeholders
 	assert.True(t, strings.HasPrefix(errorMsg, "[DebugSqlizer error: "))
@@ -31,11 +31,22 @@ func (b StatementBuilderType) Delete(from string) DeleteBuilder {
 	return DeleteBuilder(b).From(from)
 }
 
+// Merge returns a MergeBuilder for this StatementBuilderType.
+func (b StatementBuilderType) Merge(into string) MergeBuilder {
+	return MergeBuilder(b).Into(into)
+}
+
 // PlaceholderFormat sets the PlaceholderFormat field for any child builders.
 func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBuilderType {
 	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
 }
 
+// Dialect sets the Dialect field, and the matching PlaceholderFormat, for any
+// child builders.
+func (b StatementBuilderType) Dialect(d Dialect) StatementBuilderType {
+	return setDialect(b, d).(StatementBuilderType)
+}
+
 // RunWith sets the RunWith field for any child builders.
 func (b StatementBuilderType) RunWith(runner BaseRunner) StatementBuilderType {
 	return setRunWith(b, runner).(StatementBuilderType)
@@ -68,6 +79,26 @@ func UnionAll(selectStatements ...SelectBuilder) SelectBuilder {
 	return unionChain("UNION ALL", selectStatements...)
 }
 
+// Intersect combines multiple SelectBuilders with INTERSECT clauses.
+func Intersect(selectStatements ...SelectBuilder) SelectBuilder {
+	return unionChain("INTERSECT", selectStatements...)
+}
+
+// IntersectAll combines multiple SelectBuilders with INTERSECT ALL clauses.
+func IntersectAll(selectStatements ...SelectBuilder) SelectBuilder {
+	return unionChain("INTERSECT ALL", selectStatements...)
+}
+
+// Except combines multiple SelectBuilders with EXCEPT clauses.
+func Except(selectStatements ...SelectBuilder) SelectBuilder {
+	return unionChain("EXCEPT", selectStatements...)
+}
+
+// ExceptAll combines multiple SelectBuilders with EXCEPT ALL clauses.
+func ExceptAll(selectStatements ...SelectBuilder) SelectBuilder {
+	return unionChain("EXCEPT ALL", selectStatements...)
+}
+
 func unionChain(operator string, selectStatements ...SelectBuilder) SelectBuilder {
 	if len(selectStatements) == 0 {
 		return Select()
@@ -111,6 +142,13 @@ func Delete(from string) DeleteBuilder {
 	return StatementBuilder.Delete(from)
 }
 
+// Merge returns a new MergeBuilder with the given target table.
+//
+// See MergeBuilder.Into.
+func Merge(into string) MergeBuilder {
+	return StatementBuilder.Merge(into)
+}
+
 // Case returns a new CaseBuilder
 // "what" represents case value
 func Case(what ...interface{}) CaseBuilder {
@@ -1,9 +1,15 @@
 package squirrel
 
 import (
+	"container/list"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Prepareer is the interface that wraps the Prepare method.
@@ -28,54 +34,332 @@ type DBProxy interface {
 // It also automatically prepares all statements sent to the underlying Preparer calls
 // for Exec, Query and QueryRow and caches the returns *sql.Stmt using the provided
 // query as the key. So that it can be automatically re-used.
+//
+// By default the cache is unbounded; use StmtCacheCapacity to limit it.
+//
+// A StmtCache is safe for concurrent use. Cached statements are looked up
+// under a read lock, and statements are prepared without holding any lock, so
+// misses for different queries are prepared in parallel while concurrent
+// misses for the same query wait for a single Prepare.
 type StmtCache struct {
-	prep  Preparer
-	cache map[string]*sql.Stmt
-	mu    sync.Mutex
+	prep       Preparer
+	capacity   int
+	invalidate func(err error) bool
+
+	mu       sync.RWMutex
+	cache    map[string]*list.Element
+	lru      *list.List // of *stmtCacheEntry, most recently added first
+	inflight map[string]*stmtCacheCall
+
+	hits, misses, evictions, invalidations atomic.Uint64
+}
+
+type stmtCacheEntry struct {
+	query string
+	stmt  *sql.Stmt
+	// used is set on every hit, when only a read lock is held, and cleared
+	// when the entry is given a second chance at eviction.
+	used atomic.Bool
+}
+
+// stmtCacheCall is a Prepare in progress, waited on by concurrent misses for
+// the same query.
+type stmtCacheCall struct {
+	done chan struct{}
+	stmt *sql.Stmt
+	err  error
+}
+
+// StmtCacheOption configures a StmtCache created with NewStmtCache.
+type StmtCacheOption func(*StmtCache)
+
+// StmtCacheCapacity limits a StmtCache to capacity prepared statements. When
+// it is full, the least recently used statement is evicted and closed to make
+// room for a new one. Zero, the default, means no limit.
+//
+// Recency is approximated with the CLOCK algorithm, so that hits need not
+// reorder the cache: a statement used since it was last considered for
+// eviction is kept and the next candidate is considered instead.
+func StmtCacheCapacity(capacity int) StmtCacheOption {
+	return func(sc *StmtCache) {
+		sc.capacity = capacity
+	}
+}
+
+// StmtCacheInvalidateOn sets the function reporting whether an error returned
+// by a cached statement means the statement is unusable, replacing
+// IsInvalidStmt. See StmtCache.Exec.
+func StmtCacheInvalidateOn(invalid func(err error) bool) StmtCacheOption {
+	return func(sc *StmtCache) {
+		sc.invalidate = invalid
+	}
+}
+
+// IsInvalidStmt reports whether err, or any error it wraps, means that a
+// prepared statement can no longer be used and must be prepared again: its
+// connection is broken (driver.ErrBadConn), it was closed, or, in PostgreSQL,
+// a schema change altered its result type ("cached plan must not change
+// result type").
+func IsInvalidStmt(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		msg := err.Error()
+		if strings.Contains(msg, "cached plan must not change result type") ||
+			msg == errStmtClosedMsg {
+			return true
+		}
+	}
+	return false
+}
+
+// errStmtClosedMsg is the message of the error returned by database/sql when
+// a closed *sql.Stmt is used.
+const errStmtClosedMsg = "sql: statement is closed"
+
+func isStmtClosed(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if err.Error() == errStmtClosedMsg {
+			return true
+		}
+	}
+	return false
+}
+
+// StmtCacheStats holds the counters of a StmtCache, as returned by Stats.
+type StmtCacheStats struct {
+	// Hits is the number of statements found in the cache, including those
+	// prepared by a concurrent call for the same query.
+	Hits uint64
+	// Misses is the number of statements that had to be prepared.
+	Misses uint64
+	// Evictions is the number of statements evicted to respect the capacity.
+	Evictions uint64
+	// Invalidations is the number of statements removed by Invalidate or
+	// after failing with an invalidation error.
+	Invalidations uint64
+	// Len is the number of statements currently cached.
+	Len int
+}
+
+func newStmtCache(prep Preparer, opts []StmtCacheOption) *StmtCache {
+	sc := &StmtCache{
+		prep:       prep,
+		cache:      make(map[string]*list.Element),
+		lru:        list.New(),
+		inflight:   make(map[string]*stmtCacheCall),
+		invalidate: IsInvalidStmt,
+	}
+	for _, opt := range opts {
+		opt(sc)
+	}
+	return sc
 }
 
 // Prepare delegates down to the underlying Preparer and caches the result
 // using the provided query as a key
 func (sc *StmtCache) Prepare(query string) (*sql.Stmt, error) {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	return sc.prepare(context.Background(), query, func() (*sql.Stmt, error) {
+		return sc.prep.Prepare(query)
+	})
+}
 
-	stmt, ok := sc.cache[query]
+// prepare returns the cached statement for query, calling prepare on a miss.
+// Callers waiting for another goroutine's prepare give up when ctx is done.
+func (sc *StmtCache) prepare(ctx context.Context, query string, prepare func() (*sql.Stmt, error)) (*sql.Stmt, error) {
+	sc.mu.RLock()
+	elem, ok := sc.cache[query]
+	sc.mu.RUnlock()
 	if ok {
-		return stmt, nil
+		return sc.hit(elem), nil
+	}
+
+	sc.mu.Lock()
+	if elem, ok := sc.cache[query]; ok {
+		sc.mu.Unlock()
+		return sc.hit(elem), nil
+	}
+	if call, ok := sc.inflight[query]; ok {
+		sc.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err == nil {
+			sc.hits.Add(1)
+		}
+		return call.stmt, call.err
+	}
+	call := &stmtCacheCall{done: make(chan struct{})}
+	sc.inflight[query] = call
+	sc.mu.Unlock()
+
+	sc.misses.Add(1)
+	call.stmt, call.err = prepare()
+
+	var evicted []*sql.Stmt
+	sc.mu.Lock()
+	delete(sc.inflight, query)
+	if call.err == nil {
+		evicted = sc.add(query, call.stmt)
+	}
+	sc.mu.Unlock()
+	close(call.done)
+
+	for _, stmt := range evicted {
+		stmt.Close()
+	}
+	return call.stmt, call.err
+}
+
+func (sc *StmtCache) hit(elem *list.Element) *sql.Stmt {
+	sc.hits.Add(1)
+	entry := elem.Value.(*stmtCacheEntry)
+	entry.used.Store(true)
+	return entry.stmt
+}
+
+// add caches stmt and returns the statements evicted to respect the capacity,
+// which the caller must close. sc.mu must be held.
+//
+// Room is made before stmt is added, so that stmt itself, which has not had
+// a chance to be used yet, is never the one evicted.
+func (sc *StmtCache) add(query string, stmt *sql.Stmt) (evicted []*sql.Stmt) {
+	for sc.capacity > 0 && sc.lru.Len() >= sc.capacity {
+		back := sc.lru.Back()
+		entry := back.Value.(*stmtCacheEntry)
+		if entry.used.Swap(false) {
+			sc.lru.MoveToFront(back)
+			continue
+		}
+		sc.lru.Remove(back)
+		delete(sc.cache, entry.query)
+		sc.evictions.Add(1)
+		if entry.stmt != nil {
+			evicted = append(evicted, entry.stmt)
+		}
 	}
-	stmt, err := sc.prep.Prepare(query)
-	if err == nil {
-		sc.cache[query] = stmt
+	sc.cache[query] = sc.lru.PushFront(&stmtCacheEntry{query: query, stmt: stmt})
+	return evicted
+}
+
+// Stats returns the hit, miss and eviction counters of the cache and the
+// number of statements it holds.
+func (sc *StmtCache) Stats() StmtCacheStats {
+	sc.mu.RLock()
+	n := sc.lru.Len()
+	sc.mu.RUnlock()
+
+	return StmtCacheStats{
+		Hits:          sc.hits.Load(),
+		Misses:        sc.misses.Load(),
+		Evictions:     sc.evictions.Load(),
+		Invalidations: sc.invalidations.Load(),
+		Len:           n,
 	}
-	return stmt, err
 }
 
 // Exec delegates down to the underlying Preparer using a prepared statement
+//
+// If the statement fails with an error for which IsInvalidStmt (or the
+// function set with StmtCacheInvalidateOn) is true, it is removed from the
+// cache and closed, and the query is prepared and run once more. A statement
+// closed by a concurrent eviction, Invalidate or Clear after it was looked up
+// is always prepared and run again. The same goes for Query, QueryRow and
+// their Context versions.
 func (sc *StmtCache) Exec(query string, args ...interface{}) (res sql.Result, err error) {
-	stmt, err := sc.Prepare(query)
-	if err != nil {
+	err = sc.run(query, sc.Prepare, func(stmt *sql.Stmt) (err error) {
+		res, err = stmt.Exec(args...)
 		return
-	}
-	return stmt.Exec(args...)
+	})
+	return
 }
 
 // Query delegates down to the underlying Preparer using a prepared statement
 func (sc *StmtCache) Query(query string, args ...interface{}) (rows *sql.Rows, err error) {
-	stmt, err := sc.Prepare(query)
-	if err != nil {
+	err = sc.run(query, sc.Prepare, func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.Query(args...)
 		return
-	}
-	return stmt.Query(args...)
+	})
+	return
 }
 
 // QueryRow delegates down to the underlying Preparer using a prepared statement
 func (sc *StmtCache) QueryRow(query string, args ...interface{}) RowScanner {
-	stmt, err := sc.Prepare(query)
-	if err != nil {
+	var row *sql.Row
+	err := sc.run(query, sc.Prepare, func(stmt *sql.Stmt) error {
+		row = stmt.QueryRow(args...)
+		return row.Err()
+	})
+	if row == nil {
 		return &Row{err: err}
 	}
-	return stmt.QueryRow(args...)
+	return row
+}
+
+// run calls fn with the statement for query returned by prepare. If fn fails
+// with an invalidation error, the statement is invalidated and fn is called
+// once more with a newly prepared one.
+//
+// A statement can also be closed by a concurrent eviction, Invalidate or
+// Clear between prepare returning it and fn using it, so fn is always called
+// again when it fails because the statement is closed, whatever sc.invalidate
+// says.
+func (sc *StmtCache) run(query string, prepare func(string) (*sql.Stmt, error), fn func(*sql.Stmt) error) error {
+	retried := false
+	for {
+		stmt, err := prepare(query)
+		if err != nil {
+			return err
+		}
+		err = fn(stmt)
+		switch {
+		case err == nil:
+			return nil
+		case isStmtClosed(err):
+		case retried || !sc.invalidate(err):
+			return err
+		default:
+			retried = true
+		}
+		sc.remove(query, stmt)
+	}
+}
+
+// Invalidate removes and closes the cached prepared statement for query, if
+// any, so that it is prepared again when next used.
+func (sc *StmtCache) Invalidate(query string) error {
+	return sc.remove(query, nil)
+}
+
+// remove removes and closes the cached statement for query if it is stmt, or
+// whatever it is if stmt is nil. A statement that was already replaced by a
+// concurrent call is left alone.
+func (sc *StmtCache) remove(query string, stmt *sql.Stmt) error {
+	sc.mu.Lock()
+	elem, ok := sc.cache[query]
+	if ok {
+		entry := elem.Value.(*stmtCacheEntry)
+		if stmt != nil && entry.stmt != stmt {
+			ok = false
+		} else {
+			stmt = entry.stmt
+			sc.lru.Remove(elem)
+			delete(sc.cache, query)
+		}
+	}
+	sc.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	sc.invalidations.Add(1)
+	if stmt == nil {
+		return nil
+	}
+	return stmt.Close()
 }
 
 // Clear removes and closes all the currently cached prepared statements
@@ -83,9 +367,10 @@ func (sc *StmtCache) Clear() (err error) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	for key, stmt := range sc.cache {
+	for key, elem := range sc.cache {
 		delete(sc.cache, key)
 
+		stmt := elem.Value.(*stmtCacheEntry).stmt
 		if stmt == nil {
 			continue
 		}
@@ -95,6 +380,8 @@ func (sc *StmtCache) Clear() (err error) {
 		}
 	}
 
+	sc.lru.Init()
+
 	if err != nil {
 		return fmt.Errorf("one or more Stmt.Close failed; last error: %v", err)
 	}
@@ -112,9 +399,7 @@ type stmtCacheProxy struct {
 	db *sql.DB
 }
 
-func NewStmtCacheProxy(db *sql.DB) DBProxyBeginner {
-	return &stmtCacheProxy{DBProxy: NewStmtCache(db), db: db}
-}
+// NOTE: NewStmtCacheProxy is defined in stmtcacher_ctx.go (Go >= 1.8) or stmtcacher_noctx.go (Go < 1.8).
 
 func (sp *stmtCacheProxy) Begin() (*sql.Tx, error) {
 	return sp.db.Begin()
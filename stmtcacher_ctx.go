@@ -5,6 +5,8 @@ package squirrel
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sync"
 )
 
 // PrepareerContext is the interface that wraps the Prepare and PrepareContext methods.
@@ -27,8 +29,8 @@ type DBProxyContext interface {
 // NewStmtCache returns a *StmtCache wrapping a PreparerContext that caches Prepared Stmts.
 //
 // Stmts are cached based on the string value of their queries.
-func NewStmtCache(prep PreparerContext) *StmtCache {
-	return &StmtCache{prep: prep, cache: make(map[string]*sql.Stmt)}
+func NewStmtCache(prep PreparerContext, opts ...StmtCacheOption) *StmtCache {
+	return newStmtCache(prep, opts)
 }
 
 // NewStmtCacher is deprecated
@@ -45,42 +47,172 @@ func (sc *StmtCache) PrepareContext(ctx context.Context, query string) (*sql.Stm
 	if !ok {
 		return nil, NoContextSupport
 	}
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-	stmt, ok := sc.cache[query]
-	if ok {
+	return sc.prepare(ctx, query, func() (*sql.Stmt, error) {
+		return ctxPrep.PrepareContext(ctx, query)
+	})
+}
+
+// ExecContext delegates down to the underlying PreparerContext using a prepared statement
+func (sc *StmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	err = sc.run(query, sc.prepareWith(ctx), func(stmt *sql.Stmt) (err error) {
+		res, err = stmt.ExecContext(ctx, args...)
+		return
+	})
+	return
+}
+
+// QueryContext delegates down to the underlying PreparerContext using a prepared statement
+func (sc *StmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	err = sc.run(query, sc.prepareWith(ctx), func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.QueryContext(ctx, args...)
+		return
+	})
+	return
+}
+
+// QueryRowContext delegates down to the underlying PreparerContext using a prepared statement
+func (sc *StmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
+	var row *sql.Row
+	err := sc.run(query, sc.prepareWith(ctx), func(stmt *sql.Stmt) error {
+		row = stmt.QueryRowContext(ctx, args...)
+		return row.Err()
+	})
+	if row == nil {
+		return &Row{err: err}
+	}
+	return row
+}
+
+func (sc *StmtCache) prepareWith(ctx context.Context) func(string) (*sql.Stmt, error) {
+	return func(query string) (*sql.Stmt, error) {
+		return sc.PrepareContext(ctx, query)
+	}
+}
+
+// StmtCacheTx is a transaction begun with StmtCache.BeginTx. It runs
+// statements with the Stmts cached by its StmtCache, rebound to the
+// transaction with sql.Tx.StmtContext, so that statements run in transactions
+// are prepared once as well.
+//
+// Rebound Stmts are closed when the transaction is committed or rolled back.
+type StmtCacheTx struct {
+	tx *sql.Tx
+	sc *StmtCache
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// BeginTx begins a transaction on the underlying Preparer, which must
+// implement TxBeginner (e.g. *sql.DB).
+func (sc *StmtCache) BeginTx(ctx context.Context, opts *sql.TxOptions) (*StmtCacheTx, error) {
+	beginner, ok := sc.prep.(TxBeginner)
+	if !ok {
+		return nil, fmt.Errorf("cannot begin a transaction on %T", sc.prep)
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &StmtCacheTx{tx: tx, sc: sc, stmts: make(map[string]*sql.Stmt)}, nil
+}
+
+// Tx returns the underlying *sql.Tx.
+func (t *StmtCacheTx) Tx() *sql.Tx {
+	return t.tx
+}
+
+// Commit commits the transaction.
+func (t *StmtCacheTx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (t *StmtCacheTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// PrepareContext returns the cached Stmt for query bound to the transaction,
+// preparing it with the StmtCache if needed.
+func (t *StmtCacheTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if stmt, ok := t.stmts[query]; ok {
 		return stmt, nil
 	}
-	stmt, err := ctxPrep.PrepareContext(ctx, query)
-	if err == nil {
-		sc.cache[query] = stmt
+	stmt, err := t.sc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	return stmt, err
+	stmt = t.tx.StmtContext(ctx, stmt)
+	t.stmts[query] = stmt
+	return stmt, nil
 }
 
-// ExecContext delegates down to the underlying PreparerContext using a prepared statement
-func (sc *StmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
-	stmt, err := sc.PrepareContext(ctx, query)
+// Prepare is PrepareContext with context.Background().
+func (t *StmtCacheTx) Prepare(query string) (*sql.Stmt, error) {
+	return t.PrepareContext(context.Background(), query)
+}
+
+// Exec executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// Query executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRow executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) QueryRow(query string, args ...interface{}) RowScanner {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
+
+// ExecContext executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := t.PrepareContext(ctx, query)
 	if err != nil {
-		return
+		return nil, err
 	}
 	return stmt.ExecContext(ctx, args...)
 }
 
-// QueryContext delegates down to the underlying PreparerContext using a prepared statement
-func (sc *StmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
-	stmt, err := sc.PrepareContext(ctx, query)
+// QueryContext executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := t.PrepareContext(ctx, query)
 	if err != nil {
-		return
+		return nil, err
 	}
 	return stmt.QueryContext(ctx, args...)
 }
 
-// QueryRowContext delegates down to the underlying PreparerContext using a prepared statement
-func (sc *StmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
-	stmt, err := sc.PrepareContext(ctx, query)
+// QueryRowContext executes query in the transaction using a cached prepared statement
+func (t *StmtCacheTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
+	stmt, err := t.PrepareContext(ctx, query)
 	if err != nil {
 		return &Row{err: err}
 	}
 	return stmt.QueryRowContext(ctx, args...)
 }
+
+// DBProxyTxBeginner is the DBProxyBeginner returned by NewStmtCacheProxy. Its
+// BeginTx begins transactions that reuse the proxy's cached statements.
+type DBProxyTxBeginner interface {
+	DBProxyBeginner
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*StmtCacheTx, error)
+}
+
+// NewStmtCacheProxy returns a DBProxyTxBeginner wrapping db that caches
+// Prepared Stmts.
+func NewStmtCacheProxy(db *sql.DB) DBProxyTxBeginner {
+	return &stmtCacheProxy{DBProxy: NewStmtCache(db), db: db}
+}
+
+// BeginTx begins a transaction using the proxy's statement cache.
+//
+// See StmtCache.BeginTx.
+func (sp *stmtCacheProxy) BeginTx(ctx context.Context, opts *sql.TxOptions) (*StmtCacheTx, error) {
+	return sp.DBProxy.(*StmtCache).BeginTx(ctx, opts)
+}
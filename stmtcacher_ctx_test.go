@@ -19,3 +19,12 @@ func TestStmtCacherPrepareContext(t *testing.T) {
 	sc.PrepareContext(ctx, query)
 	assert.Equal(t, 1, db.PrepareCount, "expected 1 Prepare, got %d", db.PrepareCount)
 }
+
+func TestStmtCacheBeginTxNotBeginner(t *testing.T) {
+	sc := NewStmtCache(&DBStub{})
+	_, err := sc.BeginTx(ctx, nil)
+	assert.Error(t, err)
+
+	err = InTx(ctx, sc, nil, func(RunnerContext) error { return nil })
+	assert.Error(t, err)
+}
@@ -2,15 +2,13 @@
 
 package squirrel
 
-import (
-	"database/sql"
-)
+import "database/sql"
 
 // NewStmtCacher returns a DBProxy wrapping prep that caches Prepared Stmts.
 //
 // Stmts are cached based on the string value of their queries.
-func NewStmtCache(prep Preparer) *StmtCache {
-	return &StmtCacher{prep: prep, cache: make(map[string]*sql.Stmt)}
+func NewStmtCache(prep Preparer, opts ...StmtCacheOption) *StmtCache {
+	return newStmtCache(prep, opts)
 }
 
 // NewStmtCacher is deprecated
@@ -19,3 +17,7 @@ func NewStmtCache(prep Preparer) *StmtCache {
 func NewStmtCacher(prep Preparer) DBProxy {
 	return NewStmtCache(prep)
 }
+
+func NewStmtCacheProxy(db *sql.DB) DBProxyBeginner {
+	return &stmtCacheProxy{DBProxy: NewStmtCache(db), db: db}
+}
@@ -1,7 +1,14 @@
 package squirrel
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,3 +31,125 @@ func TestStmtCachePrepare(t *testing.T) {
 	sc.Prepare(query)
 	assert.Equal(t, 2, db.PrepareCount, "expected 2 Prepare, got %d", db.PrepareCount)
 }
+
+func TestStmtCacheCapacity(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCache(db, StmtCacheCapacity(2))
+
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 2")
+	sc.Prepare("SELECT 1") // SELECT 2 is now least recently used
+	sc.Prepare("SELECT 3")
+	assert.Equal(t, 3, db.PrepareCount)
+
+	sc.Prepare("SELECT 1")
+	assert.Equal(t, 3, db.PrepareCount)
+
+	sc.Prepare("SELECT 2")
+	assert.Equal(t, 4, db.PrepareCount)
+
+	expected := StmtCacheStats{Hits: 2, Misses: 4, Evictions: 2, Len: 2}
+	assert.Equal(t, expected, sc.Stats())
+
+	assert.Nil(t, sc.Clear())
+	assert.Equal(t, 0, sc.Stats().Len)
+}
+
+func TestStmtCacheCapacityKeepsNewStmt(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCache(db, StmtCacheCapacity(2))
+
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 2")
+	sc.Prepare("SELECT 1")
+	sc.Prepare("SELECT 2") // both are now marked as used
+	sc.Prepare("SELECT 3")
+	assert.Equal(t, 3, db.PrepareCount)
+
+	sc.Prepare("SELECT 3")
+	assert.Equal(t, 3, db.PrepareCount)
+
+	expected := StmtCacheStats{Hits: 3, Misses: 3, Evictions: 1, Len: 2}
+	assert.Equal(t, expected, sc.Stats())
+}
+
+func TestStmtCacheInvalidate(t *testing.T) {
+	db := &DBStub{}
+	sc := NewStmtCache(db)
+
+	sc.Prepare("SELECT 1")
+	assert.NoError(t, sc.Invalidate("SELECT 1"))
+	assert.NoError(t, sc.Invalidate("SELECT 2"))
+
+	sc.Prepare("SELECT 1")
+	assert.Equal(t, 2, db.PrepareCount)
+	assert.Equal(t, uint64(1), sc.Stats().Invalidations)
+}
+
+func TestIsInvalidStmt(t *testing.T) {
+	assert.True(t, IsInvalidStmt(driver.ErrBadConn))
+	assert.True(t, IsInvalidStmt(fmt.Errorf("exec: %w", driver.ErrBadConn)))
+	assert.True(t, IsInvalidStmt(errors.New("pq: cached plan must not change result type")))
+	assert.True(t, IsInvalidStmt(errors.New("sql: statement is closed")))
+	assert.False(t, IsInvalidStmt(errors.New("pq: syntax error")))
+	assert.False(t, IsInvalidStmt(nil))
+}
+
+// blockingPreparer counts Prepare calls per query and blocks them until
+// release is closed.
+type blockingPreparer struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	started chan string
+	release chan struct{}
+}
+
+func (p *blockingPreparer) Prepare(query string) (*sql.Stmt, error) {
+	p.mu.Lock()
+	p.counts[query]++
+	p.mu.Unlock()
+	p.started <- query
+	<-p.release
+	return nil, nil
+}
+
+func (p *blockingPreparer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.Prepare(query)
+}
+
+func TestStmtCacheConcurrentPrepare(t *testing.T) {
+	prep := &blockingPreparer{
+		counts:  make(map[string]int),
+		started: make(chan string, 2),
+		release: make(chan struct{}),
+	}
+	sc := NewStmtCache(prep)
+	queries := []string{"SELECT 1", "SELECT 2"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		query := queries[i%len(queries)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sc.PrepareContext(context.Background(), query)
+			assert.NoError(t, err)
+		}()
+	}
+
+	// Both queries must be prepared at the same time, not one after the other.
+	for range queries {
+		select {
+		case <-prep.started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("misses for different queries were not prepared in parallel")
+		}
+	}
+	close(prep.release)
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"SELECT 1": 1, "SELECT 2": 1}, prep.counts)
+	stats := sc.Stats()
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(18), stats.Hits)
+}
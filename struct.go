@@ -0,0 +1,228 @@
+package squirrel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structTag is the struct field tag used to map fields to column and
+// parameter names, e.g. `db:"created_at"`.
+const structTag = "db"
+
+// structField describes a struct field mapped to a column.
+type structField struct {
+	name  string
+	index []int
+
+	// Options from the struct tag, e.g. `db:"id,pk,omitempty"`.
+	omitEmpty bool // not written when it holds its zero value
+	readOnly  bool // never written, e.g. a generated column
+	pk        bool // part of the primary key; not SET by UpdateBuilder.SetStruct
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the mapped fields of struct type t, in declaration
+// order. Fields of embedded structs are promoted unless the embedded field
+// has a tag name of its own. Unexported fields and fields tagged `db:"-"` are
+// skipped; untagged fields are mapped by their Go name.
+//
+// Names mapped by several fields are resolved like Go resolves promoted
+// fields: the shallowest field wins, and if there are several at that depth
+// the name is ambiguous and none of them is mapped.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields := dominantFields(appendStructFields(nil, t, nil))
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// dominantFields returns fields without the fields shadowed by, or ambiguous
+// with, another field of the same name.
+func dominantFields(fields []structField) []structField {
+	type depthCount struct{ depth, count int }
+	shallowest := make(map[string]depthCount, len(fields))
+	for _, f := range fields {
+		dc, ok := shallowest[f.name]
+		switch {
+		case !ok || len(f.index) < dc.depth:
+			shallowest[f.name] = depthCount{len(f.index), 1}
+		case len(f.index) == dc.depth:
+			shallowest[f.name] = depthCount{dc.depth, dc.count + 1}
+		}
+	}
+
+	dominant := fields[:0]
+	for _, f := range fields {
+		if dc := shallowest[f.name]; dc.depth == len(f.index) && dc.count == 1 {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+func appendStructFields(fields []structField, t reflect.Type, index []int) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(structTag)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = appendStructFields(fields, ft, fieldIndex)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := structField{name: name, index: fieldIndex}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "readonly":
+				field.readOnly = true
+			case "pk":
+				field.pk = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// StructOption selects the struct fields written by InsertBuilder.SetStruct,
+// InsertBuilder.ValuesStructs and UpdateBuilder.SetStruct.
+type StructOption func(*structOptions)
+
+type structOptions struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// IncludeColumns limits the written fields to the given columns.
+func IncludeColumns(columns ...string) StructOption {
+	return func(o *structOptions) {
+		if o.include == nil {
+			o.include = make(map[string]bool)
+		}
+		for _, col := range columns {
+			o.include[col] = true
+		}
+	}
+}
+
+// ExcludeColumns skips the fields of the given columns.
+func ExcludeColumns(columns ...string) StructOption {
+	return func(o *structOptions) {
+		if o.exclude == nil {
+			o.exclude = make(map[string]bool)
+		}
+		for _, col := range columns {
+			o.exclude[col] = true
+		}
+	}
+}
+
+// writableFields returns the fields of struct type t, in declaration order,
+// that are not readonly and are selected by opts.
+func writableFields(t reflect.Type, opts []StructOption) []structField {
+	var o structOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var fields []structField
+	for _, f := range structFields(t) {
+		if f.readOnly || o.exclude[f.name] || (o.include != nil && !o.include[f.name]) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldValue returns the value of field f of struct v, and whether it is the
+// zero value. A field behind a nil embedded pointer is a zero nil.
+func fieldValue(v reflect.Value, f structField) (interface{}, bool) {
+	fv, ok := fieldByIndex(v, f.index)
+	if !ok {
+		return nil, true
+	}
+	return fv.Interface(), fv.IsZero()
+}
+
+// mustIndirectStruct is indirectStruct for the struct methods of the builders,
+// which panic when given anything but a struct, like Where does for an
+// unsupported pred.
+func mustIndirectStruct(s interface{}, method string) reflect.Value {
+	v, ok := indirectStruct(reflect.ValueOf(s))
+	if !ok {
+		panic(fmt.Sprintf("%s: expected a struct or pointer to struct, not %T", method, s))
+	}
+	return v
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false instead
+// of panicking when it meets a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndexAlloc is like reflect.Value.FieldByIndex, but allocates nil
+// embedded pointers on the way, so that the field can be set. It reports false
+// if such a pointer is unexported and cannot be allocated.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// indirectStruct dereferences pointers in v and reports whether the result is
+// a struct.
+func indirectStruct(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct
+}
@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type structFieldsBase struct {
+	ID int `db:"id"`
+}
+
+type structFieldsModel struct {
+	*structFieldsBase
+	Name     string           `db:"name,omitempty"`
+	Nested   structFieldsBase `db:"nested"`
+	Skipped  int              `db:"-"`
+	Untagged bool
+	private  int
+}
+
+func TestStructFields(t *testing.T) {
+	fields := structFields(reflect.TypeOf(structFieldsModel{}))
+
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	assert.Equal(t, []string{"id", "name", "nested", "Untagged"}, names)
+	assert.Equal(t, []int{0, 0}, fields[0].index)
+}
+
+func TestFieldByIndexNilEmbedded(t *testing.T) {
+	v := reflect.ValueOf(structFieldsModel{Name: "x"})
+	fields := structFields(v.Type())
+
+	_, ok := fieldByIndex(v, fields[0].index)
+	assert.False(t, ok)
+
+	name, ok := fieldByIndex(v, fields[1].index)
+	assert.True(t, ok)
+	assert.Equal(t, "x", name.Interface())
+}
+
+func TestStructFieldOptions(t *testing.T) {
+	type model struct {
+		ID      int    `db:"id,pk,omitempty"`
+		Created string `db:"created,readonly"`
+		Name    string `db:"name"`
+	}
+	fields := structFields(reflect.TypeOf(model{}))
+	assert.True(t, fields[0].pk)
+	assert.True(t, fields[0].omitEmpty)
+	assert.True(t, fields[1].readOnly)
+	assert.False(t, fields[2].pk || fields[2].omitEmpty || fields[2].readOnly)
+
+	var names []string
+	for _, f := range writableFields(reflect.TypeOf(model{}), []StructOption{ExcludeColumns("name")}) {
+		names = append(names, f.name)
+	}
+	assert.Equal(t, []string{"id"}, names)
+}
+
+type structShadowA struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+	Note string `db:"note"`
+}
+
+type structShadowB struct {
+	Note string `db:"note"`
+}
+
+type structShadowModel struct {
+	structShadowA
+	*structShadowB
+	Name string `db:"name"`
+}
+
+func TestStructFieldsShadowing(t *testing.T) {
+	fields := structFields(reflect.TypeOf(structShadowModel{}))
+
+	var names []string
+	var indexes [][]int
+	for _, f := range fields {
+		names = append(names, f.name)
+		indexes = append(indexes, f.index)
+	}
+	// name is shadowed by the outer field and note is ambiguous.
+	assert.Equal(t, []string{"id", "name"}, names)
+	assert.Equal(t, [][]int{{0, 0}, {2}}, indexes)
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// RecordedSpan is a span recorded by a Recorder.
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+	Parent     *RecordedSpan
+}
+
+// Recorder is an in-memory Tracer that records every span it starts, for
+// tests. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type recorderKey struct{}
+
+// Start implements Tracer.
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &RecordedSpan{Name: name, Attributes: make(map[string]interface{})}
+	s.Parent, _ = ctx.Value(recorderKey{}).(*RecordedSpan)
+
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, recorderKey{}, s), &recordedSpan{r: r, s: s}
+}
+
+// Spans returns copies of the recorded spans, in the order they were started.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, len(r.spans))
+	for i, s := range r.spans {
+		spans[i] = *s
+		spans[i].Attributes = make(map[string]interface{}, len(s.Attributes))
+		for k, v := range s.Attributes {
+			spans[i].Attributes[k] = v
+		}
+		spans[i].Errors = append([]error(nil), s.Errors...)
+	}
+	return spans
+}
+
+// Reset forgets all recorded spans.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+type recordedSpan struct {
+	r *Recorder
+	s *RecordedSpan
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	for _, a := range attrs {
+		s.s.Attributes[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Errors = append(s.s.Errors, err)
+}
+
+func (s *recordedSpan) End() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Ended = true
+}
@@ -0,0 +1,186 @@
+// Package tracing creates a span for each statement executed through its
+// ExecContextWith, QueryContextWith and QueryRowContextWith functions, which
+// wrap the squirrel functions of the same names.
+//
+// Spans are created with a Tracer, a minimal interface that adapters for
+// tracing libraries such as OpenTelemetry implement; Recorder is an in-memory
+// Tracer for tests. Each span is named after the statement kind and table,
+// e.g. "SELECT users", and has these attributes:
+//
+//	db.statement      the SQL, with placeholders intact and args left out
+//	db.operation      the statement kind, e.g. SELECT
+//	db.sql.table      the table from From, Into or Table, if known
+//	db.rows_affected  rows affected by ExecContextWith, if reported
+//
+// Errors from building or running the statement are recorded on the span.
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lann/builder"
+)
+
+// Attribute keys set on statement spans.
+const (
+	StatementKey    = "db.statement"
+	OperationKey    = "db.operation"
+	TableKey        = "db.sql.table"
+	RowsAffectedKey = "db.rows_affected"
+)
+
+// Attribute is a key/value pair set on a Span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx and returns a context
+	// holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// ExecContextWith ExecContexts the SQL returned by s with db in a span started
+// with t.
+func ExecContextWith(ctx context.Context, t Tracer, db sq.ExecerContext, s sq.Sqlizer) (sql.Result, error) {
+	ctx, span, query, args, err := start(ctx, t, s)
+	defer span.End()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return res, err
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(Attribute{Key: RowsAffectedKey, Value: n})
+	}
+	return res, nil
+}
+
+// QueryContextWith QueryContexts the SQL returned by s with db in a span
+// started with t. The span ends when the query returns, before the rows are
+// read.
+func QueryContextWith(ctx context.Context, t Tracer, db sq.QueryerContext, s sq.Sqlizer) (*sql.Rows, error) {
+	ctx, span, query, args, err := start(ctx, t, s)
+	defer span.End()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return rows, err
+}
+
+// QueryRowContextWith QueryRowContexts the SQL returned by s with db in a
+// span started with t. The span ends on the first call to Scan, where
+// database/sql reports the errors of QueryRow.
+func QueryRowContextWith(ctx context.Context, t Tracer, db sq.QueryRowerContext, s sq.Sqlizer) sq.RowScanner {
+	ctx, span, query, args, err := start(ctx, t, s)
+	if err != nil {
+		span.End()
+		return &row{err: err}
+	}
+	return &row{RowScanner: db.QueryRowContext(ctx, query, args...), span: span}
+}
+
+type row struct {
+	sq.RowScanner
+	span Span
+	err  error
+	once sync.Once
+}
+
+func (r *row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	err := r.RowScanner.Scan(dest...)
+	r.once.Do(func() {
+		if err != nil && err != sql.ErrNoRows {
+			r.span.RecordError(err)
+		}
+		r.span.End()
+	})
+	return err
+}
+
+// start builds s and starts its span. A build error is recorded on the span.
+func start(ctx context.Context, t Tracer, s sq.Sqlizer) (context.Context, Span, string, []interface{}, error) {
+	query, args, err := s.ToSql()
+	kind, table := describe(s, query)
+
+	name := kind
+	if table != "" {
+		name += " " + table
+	}
+	ctx, span := t.Start(ctx, name)
+
+	attrs := []Attribute{{Key: OperationKey, Value: kind}}
+	if err == nil {
+		attrs = append(attrs, Attribute{Key: StatementKey, Value: query})
+	}
+	if table != "" {
+		attrs = append(attrs, Attribute{Key: TableKey, Value: table})
+	}
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return ctx, span, query, args, err
+}
+
+// describe returns the statement kind and table of s. Statement builders are
+// described from their data; other Sqlizers by the first word of query.
+func describe(s sq.Sqlizer, query string) (kind, table string) {
+	switch s.(type) {
+	case sq.SelectBuilder:
+		kind = "SELECT"
+		if from, ok := builder.Get(s, "From"); ok && from != nil {
+			if fromSql, _, err := from.(sq.Sqlizer).ToSql(); err == nil && !strings.HasPrefix(fromSql, "(") {
+				table = fromSql
+			}
+		}
+	case sq.InsertBuilder:
+		kind = "INSERT"
+		if keyword, ok := builder.Get(s, "StatementKeyword"); ok && keyword != "" {
+			kind = keyword.(string)
+		}
+		table = getString(s, "Into")
+	case sq.UpdateBuilder:
+		kind, table = "UPDATE", getString(s, "Table")
+	case sq.DeleteBuilder:
+		kind, table = "DELETE", getString(s, "From")
+	case sq.MergeBuilder:
+		kind, table = "MERGE", getString(s, "Into")
+	default:
+		if fields := strings.Fields(query); len(fields) > 0 {
+			kind = strings.ToUpper(fields[0])
+		}
+	}
+	return kind, table
+}
+
+func getString(b interface{}, name string) string {
+	val, _ := builder.Get(b, name)
+	str, _ := val.(string)
+	return str
+}
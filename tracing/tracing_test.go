@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+)
+
+var errStub = errors.New("stub error")
+
+type dbStub struct {
+	err       error
+	lastQuery string
+	lastArgs  []interface{}
+}
+
+func (db *dbStub) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.lastQuery, db.lastArgs = query, args
+	return driver.RowsAffected(3), db.err
+}
+
+func (db *dbStub) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.lastQuery, db.lastArgs = query, args
+	return nil, db.err
+}
+
+func (db *dbStub) QueryRowContext(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	db.lastQuery, db.lastArgs = query, args
+	return rowStub{db.err}
+}
+
+type rowStub struct{ err error }
+
+func (r rowStub) Scan(...interface{}) error { return r.err }
+
+func TestExecContextWith(t *testing.T) {
+	rec := NewRecorder()
+	db := &dbStub{}
+	b := sq.Update("users").Set("name", "secret").Where(sq.Eq{"id": 1}).PlaceholderFormat(sq.Dollar)
+
+	_, err := ExecContextWith(context.Background(), rec, db, b)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"secret", 1}, db.lastArgs)
+
+	spans := rec.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "UPDATE users", spans[0].Name)
+	assert.True(t, spans[0].Ended)
+	assert.Empty(t, spans[0].Errors)
+	assert.Equal(t, map[string]interface{}{
+		StatementKey:    "UPDATE users SET name = $1 WHERE id = $2",
+		OperationKey:    "UPDATE",
+		TableKey:        "users",
+		RowsAffectedKey: int64(3),
+	}, spans[0].Attributes)
+}
+
+func TestQueryContextWithError(t *testing.T) {
+	rec := NewRecorder()
+	db := &dbStub{err: errStub}
+
+	_, err := QueryContextWith(context.Background(), rec, db, sq.Select("id").From("users u"))
+	assert.Equal(t, errStub, err)
+
+	spans := rec.Spans()
+	assert.Equal(t, "SELECT users u", spans[0].Name)
+	assert.Equal(t, []error{errStub}, spans[0].Errors)
+	assert.True(t, spans[0].Ended)
+}
+
+func TestQueryRowContextWith(t *testing.T) {
+	rec := NewRecorder()
+	db := &dbStub{}
+
+	row := QueryRowContextWith(context.Background(), rec, db, sq.Insert("t").Values(1).Returning("id"))
+	assert.False(t, rec.Spans()[0].Ended)
+
+	assert.NoError(t, row.Scan())
+	span := rec.Spans()[0]
+	assert.True(t, span.Ended)
+	assert.Equal(t, "INSERT t", span.Name)
+	assert.Equal(t, "INSERT INTO t VALUES (?) RETURNING id", span.Attributes[StatementKey])
+}
+
+func TestBuildError(t *testing.T) {
+	rec := NewRecorder()
+	db := &dbStub{}
+
+	_, err := ExecContextWith(context.Background(), rec, db, sq.Delete(""))
+	assert.Error(t, err)
+	assert.Empty(t, db.lastQuery)
+
+	span := rec.Spans()[0]
+	assert.Equal(t, "DELETE", span.Name)
+	assert.Len(t, span.Errors, 1)
+	assert.True(t, span.Ended)
+
+	err = QueryRowContextWith(context.Background(), rec, db, sq.Select()).Scan()
+	assert.Error(t, err)
+	assert.True(t, rec.Spans()[1].Ended)
+}
+
+func TestDescribeSqlizer(t *testing.T) {
+	rec := NewRecorder()
+	ctx, _ := rec.Start(context.Background(), "parent")
+
+	_, err := ExecContextWith(ctx, rec, &dbStub{}, sq.Expr("vacuum analyze"))
+	assert.NoError(t, err)
+
+	spans := rec.Spans()
+	assert.Equal(t, "VACUUM", spans[1].Name)
+	assert.Equal(t, "parent", spans[1].Parent.Name)
+	assert.NotContains(t, spans[1].Attributes, TableKey)
+}
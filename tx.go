@@ -0,0 +1,209 @@
+package squirrel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// TxBeginner is the interface that wraps the BeginTx method.
+//
+// BeginTx starts a transaction as implemented by database/sql.DB.BeginTx.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// TxOptions configures InTx.
+type TxOptions struct {
+	// TxOptions are passed to BeginTx.
+	sql.TxOptions
+
+	// MaxAttempts is the number of times the transaction is run before a
+	// retryable error is returned. Zero means 3; 1 disables retries.
+	MaxAttempts int
+
+	// Retryable reports whether a transaction that failed with err should be
+	// run again. Nil means IsRetryable.
+	Retryable func(err error) bool
+
+	// Backoff returns how long to wait before the given retry, starting at 1.
+	// Nil means 10ms doubled on each retry.
+	Backoff func(retry int) time.Duration
+}
+
+func (o *TxOptions) maxAttempts() int {
+	if o == nil || o.MaxAttempts <= 0 {
+		return 3
+	}
+	return o.MaxAttempts
+}
+
+func (o *TxOptions) retryable(err error) bool {
+	if o == nil || o.Retryable == nil {
+		return IsRetryable(err)
+	}
+	return o.Retryable(err)
+}
+
+func (o *TxOptions) backoff(retry int) time.Duration {
+	if o == nil || o.Backoff == nil {
+		return 10 * time.Millisecond << (retry - 1)
+	}
+	return o.Backoff(retry)
+}
+
+// IsRetryable reports whether err, or any error it wraps, is a serialization
+// failure (SQLSTATE 40001) or deadlock (SQLSTATE 40P01, MySQL error 1213),
+// after which a transaction can succeed if run again.
+//
+// SQLSTATEs are read from errors with a SQLState() string method, as returned
+// by lib/pq and pgx; MySQL error numbers from the Number field of
+// go-sql-driver/mysql errors.
+func IsRetryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(interface{ SQLState() string }); ok {
+			switch e.SQLState() {
+			case "40001", "40P01":
+				return true
+			}
+		}
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			if n := v.FieldByName("Number"); n.IsValid() && n.Kind() == reflect.Uint16 && n.Uint() == 1213 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// txRunner is the RunnerContext passed to the function run by InTx.
+type txRunner struct {
+	RunnerContext
+	depth int
+}
+
+// InTx runs fn in a transaction begun on db, committing it if fn returns nil
+// and rolling it back if fn returns an error or panics.
+//
+// db is usually a *sql.DB. If fn fails with an error for which
+// opts.Retryable is true, or the commit does, the transaction is rolled back
+// and fn is run again in a new one, up to opts.MaxAttempts times with
+// opts.Backoff between attempts. opts may be nil.
+//
+// If db is a *StmtCache, or the DBProxyTxBeginner returned by
+// NewStmtCacheProxy, the transaction is begun with its BeginTx so that fn runs
+// statements with the cached prepared statements.
+//
+// If db is the RunnerContext passed to fn by an enclosing InTx, a *sql.Tx or a
+// *StmtCacheTx, the nested fn runs within a SAVEPOINT instead: it is released
+// if fn returns nil and rolled back to otherwise, leaving the enclosing
+// transaction usable.
+// Nested calls are not retried on their own; retryable errors should be
+// returned so that the outermost transaction is retried.
+func InTx(ctx context.Context, db BaseRunner, opts *TxOptions, fn func(tx RunnerContext) error) error {
+	switch tx := db.(type) {
+	case *txRunner:
+		return inSavepoint(ctx, tx, fn)
+	case *sql.Tx:
+		return inSavepoint(ctx, &txRunner{RunnerContext: WrapStdSqlCtx(tx)}, fn)
+	case *StmtCacheTx:
+		return inSavepoint(ctx, &txRunner{RunnerContext: tx}, fn)
+	}
+
+	var txOpts *sql.TxOptions
+	if opts != nil {
+		txOpts = &opts.TxOptions
+	}
+
+	var begin func() (*sql.Tx, RunnerContext, error)
+	switch beginner := db.(type) {
+	case stmtCacheTxBeginner:
+		begin = func() (*sql.Tx, RunnerContext, error) {
+			tx, err := beginner.BeginTx(ctx, txOpts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return tx.Tx(), tx, nil
+		}
+	case TxBeginner:
+		begin = func() (*sql.Tx, RunnerContext, error) {
+			tx, err := beginner.BeginTx(ctx, txOpts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return tx, WrapStdSqlCtx(tx), nil
+		}
+	default:
+		return fmt.Errorf("cannot begin a transaction on %T", db)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(begin, fn)
+		if err == nil || attempt >= opts.maxAttempts() || !opts.retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(opts.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// stmtCacheTxBeginner is implemented by StmtCache and DBProxyTxBeginner.
+type stmtCacheTxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*StmtCacheTx, error)
+}
+
+func runTx(begin func() (*sql.Tx, RunnerContext, error), fn func(tx RunnerContext) error) (err error) {
+	tx, runner, err := begin()
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(&txRunner{RunnerContext: runner}); err != nil {
+		return err
+	}
+	committed = true
+	return tx.Commit()
+}
+
+func inSavepoint(ctx context.Context, tx *txRunner, fn func(tx RunnerContext) error) (err error) {
+	nested := &txRunner{RunnerContext: tx.RunnerContext, depth: tx.depth + 1}
+	name := fmt.Sprintf("sq_savepoint_%d", nested.depth)
+
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	released := false
+	defer func() {
+		if !released {
+			tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		}
+	}()
+
+	if err = fn(nested); err != nil {
+		return err
+	}
+	released = true
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
@@ -0,0 +1,49 @@
+package squirrel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+type mysqlError struct {
+	Number uint16
+}
+
+func (e *mysqlError) Error() string { return fmt.Sprintf("mysql error %d", e.Number) }
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(sqlStateError("40001")))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", sqlStateError("40P01"))))
+	assert.False(t, IsRetryable(sqlStateError("23505")))
+	assert.True(t, IsRetryable(&mysqlError{Number: 1213}))
+	assert.False(t, IsRetryable(&mysqlError{Number: 1062}))
+	assert.False(t, IsRetryable(errors.New("40001")))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestTxOptionsDefaults(t *testing.T) {
+	var opts *TxOptions
+	assert.Equal(t, 3, opts.maxAttempts())
+	assert.Equal(t, 10*time.Millisecond, opts.backoff(1))
+	assert.Equal(t, 40*time.Millisecond, opts.backoff(3))
+	assert.True(t, opts.retryable(sqlStateError("40001")))
+
+	opts = &TxOptions{MaxAttempts: 1, Retryable: func(error) bool { return false }}
+	assert.Equal(t, 1, opts.maxAttempts())
+	assert.False(t, opts.retryable(sqlStateError("40001")))
+}
+
+func TestInTxNoBeginner(t *testing.T) {
+	err := InTx(context.Background(), &DBStub{}, nil, func(tx RunnerContext) error { return nil })
+	assert.EqualError(t, err, "cannot begin a transaction on *squirrel.DBStub")
+}
@@ -12,6 +12,7 @@ import (
 
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
 	RunWith           BaseRunner
 	Prefixes          []Sqlizer
 	CTEs              []Sqlizer
@@ -23,7 +24,10 @@ type updateData struct {
 	OrderBys          []string
 	Limit             string
 	Offset            string
+	Returning         []Sqlizer
 	Suffixes          []Sqlizer
+	Comment           map[string]string
+	CommentPrefix     bool
 }
 
 type setClause struct {
@@ -31,6 +35,63 @@ type setClause struct {
 	value  interface{}
 }
 
+// newSetClauses converts a map[string]interface{} (or Eq) of columns to
+// values into set clauses sorted by column, like SetMap. Any other value is
+// taken to be a Sqlizer holding a whole SET list.
+func newSetClauses(set interface{}) []interface{} {
+	var clauses map[string]interface{}
+	switch s := set.(type) {
+	case map[string]interface{}:
+		clauses = s
+	case Eq:
+		clauses = s
+	default:
+		return []interface{}{setClause{value: set}}
+	}
+
+	cols := getSortedKeys(clauses)
+	parts := make([]interface{}, len(cols))
+	for i, col := range cols {
+		parts[i] = setClause{column: col, value: clauses[col]}
+	}
+	return parts
+}
+
+// setClausesToSql renders clauses as a comma separated list of assignments.
+// Sqlizer values are passed through adapt, if not nil, before rendering; a
+// clause without a column is rendered as its value alone.
+func setClausesToSql(clauses []setClause, adapt func(Sqlizer) Sqlizer) (string, []interface{}, error) {
+	var args []interface{}
+	setSqls := make([]string, len(clauses))
+	for i, setClause := range clauses {
+		var valSql string
+		if vs, ok := setClause.value.(Sqlizer); ok {
+			if adapt != nil {
+				vs = adapt(vs)
+			}
+			vsql, vargs, err := nestedToSql(vs)
+			if err != nil {
+				return "", nil, err
+			}
+			if _, ok := vs.(SelectBuilder); ok {
+				valSql = fmt.Sprintf("(%s)", vsql)
+			} else {
+				valSql = vsql
+			}
+			args = append(args, vargs...)
+		} else {
+			valSql = "?"
+			args = append(args, setClause.value)
+		}
+		if setClause.column == "" {
+			setSqls[i] = valSql
+		} else {
+			setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
+		}
+	}
+	return strings.Join(setSqls, ", "), args, nil
+}
+
 func (d *updateData) Exec() (sql.Result, error) {
 	if d.RunWith == nil {
 		return nil, RunnerNotSet
@@ -57,6 +118,18 @@ func (d *updateData) QueryRow() RowScanner {
 }
 
 func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
+	sqlStr, args, err = d.toSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr = withComment(sqlStr, d.Comment, d.CommentPrefix)
+
+	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	return
+}
+
+func (d *updateData) toSqlRaw() (sqlStr string, args []interface{}, err error) {
 	if len(d.Table) == 0 {
 		err = fmt.Errorf("update statements must specify a table")
 		return
@@ -79,7 +152,7 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.CTEs) > 0 {
 		sql.WriteString("WITH ")
-		args, err = appendToSql(d.CTEs, sql, ", ", args)
+		args, err = appendToSql(applyDialect(d.CTEs, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return
 		}
@@ -87,42 +160,49 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 	}
 
 	sql.WriteString("UPDATE ")
+
+	top, limitClause, err := dmlLimitOffset(d.Dialect, StatementUpdate, d.Limit, d.Offset)
+	if err != nil {
+		return
+	}
+	if len(top) > 0 {
+		sql.WriteString(top)
+		sql.WriteString(" ")
+	}
+
 	sql.WriteString(d.Table)
 
 	if len(d.Joins) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
+		args, err = appendToSql(applyDialect(d.Joins, d.Dialect), sql, " ", args)
 		if err != nil {
 			return
 		}
 	}
 
 	sql.WriteString(" SET ")
-	setSqls := make([]string, len(d.SetClauses))
-	for i, setClause := range d.SetClauses {
-		var valSql string
-		if vs, ok := setClause.value.(Sqlizer); ok {
-			vsql, vargs, err := vs.ToSql()
-			if err != nil {
-				return "", nil, err
-			}
-			if _, ok := vs.(SelectBuilder); ok {
-				valSql = fmt.Sprintf("(%s)", vsql)
-			} else {
-				valSql = vsql
-			}
-			args = append(args, vargs...)
-		} else {
-			valSql = "?"
-			args = append(args, setClause.value)
-		}
-		setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
+	setSql, setArgs, err := setClausesToSql(d.SetClauses, func(vs Sqlizer) Sqlizer {
+		return adaptToDialect(vs, d.Dialect)
+	})
+	if err != nil {
+		return
+	}
+	sql.WriteString(setSql)
+	args = append(args, setArgs...)
+
+	output, outputArgs, err := returningToSql(d.Dialect, StatementUpdate, ReturningOutput, d.Returning, "INSERTED")
+	if err != nil {
+		return
+	}
+	if len(output) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(output)
+		args = append(args, outputArgs...)
 	}
-	sql.WriteString(strings.Join(setSqls, ", "))
 
 	if d.From != nil {
 		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSql([]Sqlizer{adaptToDialect(d.From, d.Dialect)}, sql, "", args)
 		if err != nil {
 			return
 		}
@@ -130,7 +210,7 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(applyDialect(d.WhereParts, d.Dialect), sql, " AND ", args)
 		if err != nil {
 			return
 		}
@@ -141,14 +221,19 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(limitClause) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(limitClause)
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
+	returning, returningArgs, err := returningToSql(d.Dialect, StatementUpdate, ReturningClause, d.Returning, "")
+	if err != nil {
+		return
+	}
+	if len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
 	}
 
 	if len(d.Suffixes) > 0 {
@@ -159,7 +244,7 @@ func (d *updateData) ToSql() (sqlStr string, args []interface{}, err error) {
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
@@ -180,6 +265,12 @@ func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
 }
 
+// Dialect sets the Dialect used to render the query, along with its default
+// PlaceholderFormat.
+func (b UpdateBuilder) Dialect(d Dialect) UpdateBuilder {
+	return setDialect(b, d).(UpdateBuilder)
+}
+
 // Runner methods
 
 // RunWith sets a Runner (like database/sql.DB) to be used with e.g. Exec.
@@ -215,6 +306,11 @@ func (b UpdateBuilder) ToSql() (string, []interface{}, error) {
 	return data.ToSql()
 }
 
+func (b UpdateBuilder) toSqlRaw() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(updateData)
+	return data.toSqlRaw()
+}
+
 // MustSql builds the query into a SQL string and bound args.
 // It panics if there are any errors.
 func (b UpdateBuilder) MustSql() (string, []interface{}) {
@@ -261,6 +357,37 @@ func (b UpdateBuilder) SetMap(clauses map[string]interface{}) UpdateBuilder {
 	return b
 }
 
+// SetStruct adds SET clauses for the fields of a struct, or pointer to struct,
+// mapped by their `db` tags, in field declaration order.
+//
+// Fields tagged readonly are skipped, as are fields tagged omitempty that hold
+// their zero value. Fields tagged pk are not SET; instead they are always
+// added to the WHERE clause, e.g. "id = ?", whatever opts say. opts include or
+// exclude further columns to SET.
+//
+// SetStruct panics if s is not a struct.
+func (b UpdateBuilder) SetStruct(s interface{}, opts ...StructOption) UpdateBuilder {
+	v := mustIndirectStruct(s, "SetStruct")
+
+	for _, f := range structFields(v.Type()) {
+		if f.pk {
+			val, _ := fieldValue(v, f)
+			b = b.Where(Eq{f.name: val})
+		}
+	}
+	for _, f := range writableFields(v.Type(), opts) {
+		if f.pk {
+			continue
+		}
+		val, zero := fieldValue(v, f)
+		if f.omitEmpty && zero {
+			continue
+		}
+		b = b.Set(f.name, val)
+	}
+	return b
+}
+
 // From adds FROM clause to the query
 // FROM is valid construct in postgresql only.
 func (b UpdateBuilder) From(from string) UpdateBuilder {
@@ -281,6 +408,13 @@ func (b UpdateBuilder) Where(pred interface{}, args ...interface{}) UpdateBuilde
 	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(UpdateBuilder)
 }
 
+// WhereNamed adds a WHERE expression with named parameters bound from params.
+//
+// See NamedExpr.
+func (b UpdateBuilder) WhereNamed(sql string, params interface{}) UpdateBuilder {
+	return b.Where(NamedExpr(sql, params))
+}
+
 // OrderBy adds ORDER BY expressions to the query.
 func (b UpdateBuilder) OrderBy(orderBys ...string) UpdateBuilder {
 	return builder.Extend(b, "OrderBys", orderBys).(UpdateBuilder)
@@ -306,6 +440,27 @@ func (b UpdateBuilder) SuffixExpr(expr Sqlizer) UpdateBuilder {
 	return builder.Append(b, "Suffixes", expr).(UpdateBuilder)
 }
 
+// SuffixNamed adds an expression with named parameters bound from params to
+// the end of the query.
+//
+// See NamedExpr.
+func (b UpdateBuilder) SuffixNamed(sql string, params interface{}) UpdateBuilder {
+	return b.SuffixExpr(NamedExpr(sql, params))
+}
+
+// Returning adds RETURNING expressions to the query.
+//
+// Under a Dialect using ReturningOutput (SQL Server) they are rendered as an
+// OUTPUT clause, with plain column names qualified by the INSERTED pseudo table.
+func (b UpdateBuilder) Returning(columns ...string) UpdateBuilder {
+	return builder.Extend(b, "Returning", newReturningColumns(columns)).(UpdateBuilder)
+}
+
+// ReturningExpr adds a RETURNING expression to the query.
+func (b UpdateBuilder) ReturningExpr(expr Sqlizer) UpdateBuilder {
+	return builder.Append(b, "Returning", expr).(UpdateBuilder)
+}
+
 // With adds a CTE to the query.
 func (b UpdateBuilder) With(alias string, expr Sqlizer) UpdateBuilder {
 	return b.WithCTE(CTE{Alias: alias, ColumnList: []string{}, Recursive: false, Expression: expr})
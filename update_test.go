@@ -243,3 +243,47 @@ func TestUpdateBuilderJoinWithParams(t *testing.T) {
 	assert.Equal(t, expectedSql, sql)
 	assert.Equal(t, []interface{}{"approved", true, "2024-01-01"}, args)
 }
+
+func TestUpdateBuilderSetStruct(t *testing.T) {
+	type user struct {
+		TenantID  int    `db:"tenant_id,pk"`
+		ID        int    `db:"id,pk"`
+		Name      string `db:"name"`
+		Email     string `db:"email,omitempty"`
+		UpdatedAt string `db:"updated_at,readonly"`
+	}
+
+	b := Update("users").
+		SetStruct(user{TenantID: 1, ID: 2, Name: "moe", UpdatedAt: "now"}).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = $1 WHERE tenant_id = $2 AND id = $3", sql)
+	assert.Equal(t, []interface{}{"moe", 1, 2}, args)
+
+	b = Update("users").
+		SetStruct(&user{TenantID: 1, ID: 2, Name: "moe", Email: "m@x"}, ExcludeColumns("tenant_id", "name"))
+
+	sql, args, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET email = ? WHERE tenant_id = ? AND id = ?", sql)
+	assert.Equal(t, []interface{}{"m@x", 1, 2}, args)
+
+	// Primary key columns stay in the WHERE clause whatever the options.
+	b = Update("users").SetStruct(user{TenantID: 1, ID: 2, Name: "x"}, IncludeColumns("name"))
+
+	sql, args, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE tenant_id = ? AND id = ?", sql)
+	assert.Equal(t, []interface{}{"x", 1, 2}, args)
+}
+
+func TestUpdateBuilderSetStructShadowed(t *testing.T) {
+	v := structShadowModel{structShadowA: structShadowA{ID: 1, Name: "inner"}, Name: "outer"}
+
+	sql, args, err := Update("users").SetStruct(v).Where("id = ?", 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET id = ?, name = ? WHERE id = ?", sql)
+	assert.Equal(t, []interface{}{1, "outer", 1}, args)
+}
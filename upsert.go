@@ -0,0 +1,178 @@
+package squirrel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lann/builder"
+)
+
+// UpsertStyle describes how a database resolves INSERT conflicts.
+type UpsertStyle int
+
+const (
+	// UpsertUnsupported means the database has no INSERT conflict clause;
+	// use a MERGE statement instead.
+	UpsertUnsupported UpsertStyle = iota
+
+	// UpsertOnConflict is the ON CONFLICT ... DO NOTHING / DO UPDATE clause of
+	// PostgreSQL and SQLite.
+	UpsertOnConflict
+
+	// UpsertOnDuplicateKey is the ON DUPLICATE KEY UPDATE clause of MySQL.
+	UpsertOnDuplicateKey
+)
+
+type excludedExpr struct {
+	column string
+	style  UpsertStyle
+}
+
+// Excluded refers to the value that would have been inserted into column,
+// for use in DoUpdateSet and OnDuplicateKeyUpdate. It renders as
+// EXCLUDED.column, or as VALUES(column) under a MySQL Dialect.
+//
+// Ex:
+//
+//	.OnConflict("id").DoUpdateSet(Eq{"name": Excluded("name")})
+func Excluded(column string) Sqlizer {
+	return excludedExpr{column: column, style: UpsertOnConflict}
+}
+
+func (e excludedExpr) ToSql() (string, []interface{}, error) {
+	if e.style == UpsertOnDuplicateKey {
+		return fmt.Sprintf("VALUES(%s)", e.column), nil, nil
+	}
+	return "EXCLUDED." + e.column, nil, nil
+}
+
+func (d *insertData) upsertStyle() UpsertStyle {
+	if d.DuplicateKey {
+		return UpsertOnDuplicateKey
+	}
+	if d.Dialect == nil {
+		return UpsertOnConflict
+	}
+	return d.Dialect.UpsertStyle()
+}
+
+func (d *insertData) appendConflictToSQL(w io.Writer, args []interface{}) ([]interface{}, error) {
+	if !d.Conflict && !d.DuplicateKey {
+		if len(d.ConflictSet) > 0 || d.ConflictAction != "" {
+			return nil, errors.New("DoNothing and DoUpdateSet require OnConflict")
+		}
+		return args, nil
+	}
+	if d.ConflictAction == "" {
+		return nil, errors.New("OnConflict requires DoNothing or DoUpdateSet")
+	}
+
+	style := d.upsertStyle()
+	switch style {
+	case UpsertOnConflict:
+		io.WriteString(w, " ON CONFLICT")
+		if d.ConflictConstraint != "" {
+			io.WriteString(w, " ON CONSTRAINT ")
+			io.WriteString(w, d.ConflictConstraint)
+		} else if len(d.ConflictTarget) > 0 {
+			io.WriteString(w, " (")
+			io.WriteString(w, strings.Join(d.ConflictTarget, ","))
+			io.WriteString(w, ")")
+		}
+		if d.ConflictAction == "NOTHING" {
+			io.WriteString(w, " DO NOTHING")
+			return args, nil
+		}
+		io.WriteString(w, " DO UPDATE SET ")
+	case UpsertOnDuplicateKey:
+		if len(d.ConflictWhereParts) > 0 {
+			return nil, errors.New("ON DUPLICATE KEY UPDATE does not support a WHERE clause")
+		}
+		if d.ConflictAction == "NOTHING" {
+			// MySQL has no DO NOTHING; a no-op update of the conflict column
+			// is the usual way to ignore only duplicate key errors.
+			if len(d.ConflictTarget) == 0 {
+				return nil, errors.New("DoNothing requires a conflict column under ON DUPLICATE KEY UPDATE")
+			}
+			col := d.ConflictTarget[0]
+			fmt.Fprintf(w, " ON DUPLICATE KEY UPDATE %s = %s", col, col)
+			return args, nil
+		}
+		io.WriteString(w, " ON DUPLICATE KEY UPDATE ")
+	default:
+		return nil, errors.New("dialect does not support INSERT conflict clauses; use Merge instead")
+	}
+
+	setSql, setArgs, err := setClausesToSql(d.ConflictSet, func(vs Sqlizer) Sqlizer {
+		if e, ok := vs.(excludedExpr); ok {
+			e.style = style
+			return e
+		}
+		return adaptToDialect(vs, d.Dialect)
+	})
+	if err != nil {
+		return nil, err
+	}
+	io.WriteString(w, setSql)
+	args = append(args, setArgs...)
+
+	if len(d.ConflictWhereParts) > 0 {
+		io.WriteString(w, " WHERE ")
+		args, err = appendToSql(applyDialect(d.ConflictWhereParts, d.Dialect), w, " AND ", args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// OnConflict adds an ON CONFLICT clause for the given conflict target columns
+// to the query. It must be followed by DoNothing or DoUpdateSet.
+//
+// Under a Dialect using UpsertOnDuplicateKey (MySQL) the clause is rendered as
+// ON DUPLICATE KEY UPDATE instead.
+func (b InsertBuilder) OnConflict(columns ...string) InsertBuilder {
+	b = builder.Set(b, "Conflict", true).(InsertBuilder)
+	return builder.Extend(b, "ConflictTarget", columns).(InsertBuilder)
+}
+
+// OnConflictOnConstraint adds an ON CONFLICT ON CONSTRAINT clause to the query.
+// It must be followed by DoNothing or DoUpdateSet.
+func (b InsertBuilder) OnConflictOnConstraint(constraint string) InsertBuilder {
+	b = builder.Set(b, "Conflict", true).(InsertBuilder)
+	return builder.Set(b, "ConflictConstraint", constraint).(InsertBuilder)
+}
+
+// DoNothing sets the action of the ON CONFLICT clause to DO NOTHING.
+func (b InsertBuilder) DoNothing() InsertBuilder {
+	return builder.Set(b, "ConflictAction", "NOTHING").(InsertBuilder)
+}
+
+// DoUpdateSet sets the action of the ON CONFLICT clause to DO UPDATE SET.
+//
+// set may be a map[string]interface{} (or Eq) of columns to values, which is
+// rendered in sorted column order like SetMap, or a Sqlizer holding the whole
+// SET list. Values may be Sqlizers, e.g. Excluded.
+func (b InsertBuilder) DoUpdateSet(set interface{}) InsertBuilder {
+	b = builder.Set(b, "ConflictAction", "UPDATE").(InsertBuilder)
+	return builder.Extend(b, "ConflictSet", newSetClauses(set)).(InsertBuilder)
+}
+
+// DoUpdateWhere adds a WHERE expression to the DO UPDATE action of the ON
+// CONFLICT clause.
+//
+// See SelectBuilder.Where for more information.
+func (b InsertBuilder) DoUpdateWhere(pred interface{}, args ...interface{}) InsertBuilder {
+	return builder.Append(b, "ConflictWhereParts", newWherePart(pred, args...)).(InsertBuilder)
+}
+
+// OnDuplicateKeyUpdate adds a MySQL ON DUPLICATE KEY UPDATE clause to the
+// query, regardless of the Dialect.
+//
+// See DoUpdateSet for the accepted values of set.
+func (b InsertBuilder) OnDuplicateKeyUpdate(set interface{}) InsertBuilder {
+	b = builder.Set(b, "DuplicateKey", true).(InsertBuilder)
+	return b.DoUpdateSet(set)
+}
@@ -0,0 +1,113 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBuilderOnConflictDoNothing(t *testing.T) {
+	b := Insert("users").Columns("id", "name").Values(1, "moe").OnConflict("id").DoNothing()
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSQL := "INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT (id) DO NOTHING"
+	assert.Equal(t, expectedSQL, sql)
+
+	expectedArgs := []interface{}{1, "moe"}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestInsertBuilderOnConflictDoUpdateSet(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name", "visits").
+		Values(1, "moe", 1).
+		OnConflict("id").
+		DoUpdateSet(map[string]interface{}{
+			"name":   Excluded("name"),
+			"visits": Expr("users.visits + ?", 1),
+			"note":   "dup",
+		}).
+		DoUpdateWhere("users.name <> ?", "larry").
+		Suffix("RETURNING id").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSQL := "INSERT INTO users (id,name,visits) VALUES ($1,$2,$3) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, note = $4, visits = users.visits + $5 " +
+		"WHERE users.name <> $6 RETURNING id"
+	assert.Equal(t, expectedSQL, sql)
+
+	expectedArgs := []interface{}{1, "moe", 1, "dup", 1, "larry"}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestInsertBuilderOnConflictSqlizerSet(t *testing.T) {
+	b := Insert("t").Values(1).
+		OnConflictOnConstraint("t_pkey").
+		DoUpdateSet(Expr("n = t.n + ?", 2))
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?) ON CONFLICT ON CONSTRAINT t_pkey DO UPDATE SET n = t.n + ?", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdate(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name").
+		Values(1, "moe").
+		OnDuplicateKeyUpdate(Eq{"name": Excluded("name"), "visits": Expr("visits + ?", 1)})
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSQL := "INSERT INTO users (id,name) VALUES (?,?) " +
+		"ON DUPLICATE KEY UPDATE name = VALUES(name), visits = visits + ?"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []interface{}{1, "moe", 1}, args)
+}
+
+func TestInsertBuilderOnConflictDialects(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name").
+		Values(1, "moe").
+		OnConflict("id").
+		DoUpdateSet(Eq{"name": Excluded("name")})
+
+	sql, _, err := b.Dialect(Postgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", sql)
+
+	sql, _, err = b.Dialect(SQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", sql)
+
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON DUPLICATE KEY UPDATE name = VALUES(name)", sql)
+
+	sql, _, err = b.DoNothing().Dialect(MySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON DUPLICATE KEY UPDATE id = id", sql)
+
+	_, _, err = b.Dialect(SQLServer).ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderOnConflictErrors(t *testing.T) {
+	_, _, err := Insert("t").Values(1).OnConflict("id").ToSql()
+	assert.EqualError(t, err, "OnConflict requires DoNothing or DoUpdateSet")
+
+	_, _, err = Insert("t").Values(1).DoNothing().ToSql()
+	assert.EqualError(t, err, "DoNothing and DoUpdateSet require OnConflict")
+
+	_, _, err = Insert("t").Values(1).
+		OnDuplicateKeyUpdate(Eq{"a": 1}).
+		DoUpdateWhere("a > ?", 0).
+		ToSql()
+	assert.Error(t, err)
+}
@@ -28,3 +28,13 @@ func (p wherePart) ToSql() (sql string, args []interface{}, err error) {
 	}
 	return
 }
+
+func (p wherePart) withDialect(d Dialect) Sqlizer {
+	switch pred := p.pred.(type) {
+	case dialectSqlizer:
+		return &wherePart{pred: pred.withDialect(d), args: p.args}
+	case map[string]interface{}:
+		return &wherePart{pred: Eq(pred).withDialect(d), args: p.args}
+	}
+	return &p
+}
@@ -0,0 +1,288 @@
+package squirrel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/lann/builder"
+)
+
+func init() {
+	builder.Register(WindowBuilder{}, windowData{})
+}
+
+// FrameBound is one end of a window frame, e.g. UnboundedPreceding or
+// Preceding(3).
+type FrameBound struct {
+	offset Sqlizer
+	bound  string
+}
+
+// ToSql implements Sqlizer.
+func (f FrameBound) ToSql() (string, []interface{}, error) {
+	if f.offset == nil {
+		return f.bound, nil, nil
+	}
+	sql, args, err := nestedToSql(f.offset)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql + " " + f.bound, args, nil
+}
+
+var (
+	// UnboundedPreceding is the frame bound UNBOUNDED PRECEDING.
+	UnboundedPreceding = FrameBound{bound: "UNBOUNDED PRECEDING"}
+
+	// CurrentRow is the frame bound CURRENT ROW.
+	CurrentRow = FrameBound{bound: "CURRENT ROW"}
+
+	// UnboundedFollowing is the frame bound UNBOUNDED FOLLOWING.
+	UnboundedFollowing = FrameBound{bound: "UNBOUNDED FOLLOWING"}
+)
+
+// Preceding returns the frame bound "offset PRECEDING". offset is bound as an
+// arg unless it is a Sqlizer, e.g. Expr("INTERVAL '1 day'").
+func Preceding(offset interface{}) FrameBound {
+	return FrameBound{offset: frameOffset(offset), bound: "PRECEDING"}
+}
+
+// Following returns the frame bound "offset FOLLOWING". offset is bound as an
+// arg unless it is a Sqlizer.
+func Following(offset interface{}) FrameBound {
+	return FrameBound{offset: frameOffset(offset), bound: "FOLLOWING"}
+}
+
+func frameOffset(offset interface{}) Sqlizer {
+	if s, ok := offset.(Sqlizer); ok {
+		return s
+	}
+	return Expr("?", offset)
+}
+
+// windowData holds all the data required to build a window specification
+type windowData struct {
+	Base         string
+	PartitionBys []Sqlizer
+	OrderBys     []Sqlizer
+	FrameUnit    string
+	FrameStart   *FrameBound
+	FrameEnd     *FrameBound
+}
+
+// ToSql implements Sqlizer
+func (d *windowData) ToSql() (sqlStr string, args []interface{}, err error) {
+	sql := &bytes.Buffer{}
+	sep := ""
+
+	if d.Base != "" {
+		sql.WriteString(d.Base)
+		sep = " "
+	}
+
+	if len(d.PartitionBys) > 0 {
+		sql.WriteString(sep)
+		sql.WriteString("PARTITION BY ")
+		args, err = appendToSql(d.PartitionBys, sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sep = " "
+	}
+
+	if len(d.OrderBys) > 0 {
+		sql.WriteString(sep)
+		sql.WriteString("ORDER BY ")
+		args, err = appendToSql(d.OrderBys, sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sep = " "
+	}
+
+	if d.FrameUnit != "" {
+		if d.FrameStart == nil {
+			err = errors.New("window frame must have a start bound")
+			return
+		}
+		sql.WriteString(sep)
+		sql.WriteString(d.FrameUnit)
+		sql.WriteString(" ")
+		bounds := []Sqlizer{*d.FrameStart}
+		if d.FrameEnd != nil {
+			sql.WriteString("BETWEEN ")
+			bounds = append(bounds, *d.FrameEnd)
+		}
+		args, err = appendToSql(bounds, sql, " AND ", args)
+		if err != nil {
+			return
+		}
+	}
+
+	sqlStr = sql.String()
+	return
+}
+
+// WindowBuilder builds window specifications for OVER and WINDOW clauses.
+type WindowBuilder builder.Builder
+
+func (b WindowBuilder) withDialect(d Dialect) Sqlizer {
+	return windowWithDialect(b, d)
+}
+
+// windowWithDialect returns b with its PARTITION BY and ORDER BY expressions
+// adapted to d.
+func windowWithDialect(b WindowBuilder, d Dialect) WindowBuilder {
+	data := builder.GetStruct(b).(windowData)
+	if len(data.PartitionBys) > 0 {
+		b = builder.Set(b, "PartitionBys", applyDialect(data.PartitionBys, d)).(WindowBuilder)
+	}
+	if len(data.OrderBys) > 0 {
+		b = builder.Set(b, "OrderBys", applyDialect(data.OrderBys, d)).(WindowBuilder)
+	}
+	return b
+}
+
+// ToSql builds the window specification, without the surrounding
+// parentheses, into a SQL string and bound args.
+func (b WindowBuilder) ToSql() (string, []interface{}, error) {
+	data := builder.GetStruct(b).(windowData)
+	return data.ToSql()
+}
+
+// MustSql builds the window specification into a SQL string and bound args.
+// It panics if there are any errors.
+func (b WindowBuilder) MustSql() (string, []interface{}) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// PartitionBy adds PARTITION BY expressions to the window.
+func (b WindowBuilder) PartitionBy(partitionBys ...string) WindowBuilder {
+	for _, p := range partitionBys {
+		b = b.PartitionByClause(p)
+	}
+	return b
+}
+
+// PartitionByClause adds a PARTITION BY expression with args to the window.
+func (b WindowBuilder) PartitionByClause(pred interface{}, args ...interface{}) WindowBuilder {
+	return builder.Append(b, "PartitionBys", newPart(pred, args...)).(WindowBuilder)
+}
+
+// OrderBy adds ORDER BY expressions to the window.
+func (b WindowBuilder) OrderBy(orderBys ...string) WindowBuilder {
+	for _, orderBy := range orderBys {
+		b = b.OrderByClause(orderBy)
+	}
+	return b
+}
+
+// OrderByClause adds an ORDER BY expression with args to the window.
+func (b WindowBuilder) OrderByClause(pred interface{}, args ...interface{}) WindowBuilder {
+	return builder.Append(b, "OrderBys", newPart(pred, args...)).(WindowBuilder)
+}
+
+// Rows sets a ROWS frame on the window. If end is given the frame is rendered
+// as ROWS BETWEEN start AND end.
+func (b WindowBuilder) Rows(start FrameBound, end ...FrameBound) WindowBuilder {
+	return b.frame("ROWS", start, end)
+}
+
+// Range sets a RANGE frame on the window. See Rows.
+func (b WindowBuilder) Range(start FrameBound, end ...FrameBound) WindowBuilder {
+	return b.frame("RANGE", start, end)
+}
+
+// Groups sets a GROUPS frame on the window. See Rows.
+func (b WindowBuilder) Groups(start FrameBound, end ...FrameBound) WindowBuilder {
+	return b.frame("GROUPS", start, end)
+}
+
+func (b WindowBuilder) frame(unit string, start FrameBound, end []FrameBound) WindowBuilder {
+	b = builder.Set(b, "FrameUnit", unit).(WindowBuilder)
+	b = builder.Set(b, "FrameStart", &start).(WindowBuilder)
+	if len(end) > 0 {
+		return builder.Set(b, "FrameEnd", &end[0]).(WindowBuilder)
+	}
+	return builder.Delete(b, "FrameEnd").(WindowBuilder)
+}
+
+// Window returns a new WindowBuilder, optionally extending the named window
+// base, e.g. Window("w").OrderBy("b") renders "w ORDER BY b".
+func Window(base ...string) WindowBuilder {
+	b := WindowBuilder(builder.EmptyBuilder)
+	if len(base) > 0 {
+		b = builder.Set(b, "Base", base[0]).(WindowBuilder)
+	}
+	return b
+}
+
+type overExpr struct {
+	fn     Sqlizer
+	window interface{}
+}
+
+// Over builds a window function call "fn OVER window" for use in Column or
+// OrderByClause. fn may be a string or a Sqlizer; window may be a WindowBuilder,
+// rendered in parentheses, or the string name of a window defined with
+// SelectBuilder.Window.
+//
+// Ex:
+//
+//	Over("ROW_NUMBER()", Window().PartitionBy("a").OrderBy("b"))
+func Over(fn interface{}, window interface{}) Sqlizer {
+	return overExpr{fn: newPart(fn), window: window}
+}
+
+func (e overExpr) withDialect(d Dialect) Sqlizer {
+	e.fn = adaptToDialect(e.fn, d)
+	if w, ok := e.window.(WindowBuilder); ok {
+		e.window = windowWithDialect(w, d)
+	}
+	return e
+}
+
+func (e overExpr) ToSql() (sql string, args []interface{}, err error) {
+	sql, args, err = nestedToSql(e.fn)
+	if err != nil {
+		return
+	}
+
+	switch w := e.window.(type) {
+	case string:
+		sql += " OVER " + w
+	case WindowBuilder:
+		wSql, wArgs, err := w.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		sql += " OVER (" + wSql + ")"
+		args = append(args, wArgs...)
+	default:
+		return "", nil, fmt.Errorf("window must be a string or WindowBuilder, not %T", e.window)
+	}
+	return
+}
+
+type windowDefinition struct {
+	name string
+	spec WindowBuilder
+}
+
+func (w windowDefinition) withDialect(d Dialect) Sqlizer {
+	w.spec = windowWithDialect(w.spec, d)
+	return w
+}
+
+func (w windowDefinition) ToSql() (string, []interface{}, error) {
+	sql, args, err := w.spec.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	return w.name + " AS (" + sql + ")", args, nil
+}
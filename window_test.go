@@ -0,0 +1,68 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowToSql(t *testing.T) {
+	w := Window().
+		PartitionBy("dept", "team").
+		OrderBy("hired_at DESC").
+		Rows(Preceding(3), CurrentRow)
+
+	sql, args, err := w.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "PARTITION BY dept, team ORDER BY hired_at DESC ROWS BETWEEN ? PRECEDING AND CURRENT ROW", sql)
+	assert.Equal(t, []interface{}{3}, args)
+}
+
+func TestWindowFrames(t *testing.T) {
+	sql, _, err := Window().OrderBy("ts").Range(UnboundedPreceding).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER BY ts RANGE UNBOUNDED PRECEDING", sql)
+
+	sql, args, err := Window().OrderBy("ts").
+		Range(Preceding(Expr("INTERVAL '1 day'")), Following(Expr("? * INTERVAL '1 hour'", 2))).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER BY ts RANGE BETWEEN INTERVAL '1 day' PRECEDING AND ? * INTERVAL '1 hour' FOLLOWING", sql)
+	assert.Equal(t, []interface{}{2}, args)
+
+	sql, _, err = Window("w").Groups(CurrentRow, UnboundedFollowing).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "w GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING", sql)
+}
+
+func TestOverInSelect(t *testing.T) {
+	b := Select("id").
+		Column(Alias(Over("ROW_NUMBER()", Window().PartitionBy("dept").OrderByClause("score > ?", 10)), "rn")).
+		Column(Over(Expr("SUM(amount) FILTER (WHERE amount > ?)", 0), "w")).
+		From("sales").
+		Where("region = ?", "eu").
+		GroupBy("id", "dept").
+		Having("COUNT(*) > ?", 1).
+		Window("w", Window().PartitionBy("dept").Rows(Preceding(5), Following(5))).
+		Window("w2", Window("w").OrderBy("id")).
+		OrderByClause(Over("RANK()", "w2")).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id, (ROW_NUMBER() OVER (PARTITION BY dept ORDER BY score > $1)) AS rn, " +
+		"SUM(amount) FILTER (WHERE amount > $2) OVER w " +
+		"FROM sales WHERE region = $3 GROUP BY id, dept HAVING COUNT(*) > $4 " +
+		"WINDOW w AS (PARTITION BY dept ROWS BETWEEN $5 PRECEDING AND $6 FOLLOWING), w2 AS (w ORDER BY id) " +
+		"ORDER BY RANK() OVER w2"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []interface{}{10, 0, "eu", 1, 5, 5}
+	assert.Equal(t, expectedArgs, args)
+}
+
+func TestOverErrors(t *testing.T) {
+	_, _, err := Over("ROW_NUMBER()", 1).ToSql()
+	assert.Error(t, err)
+}